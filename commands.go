@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// maxCommandDepth limits the depth of the subcommands in case of a loop
+const maxCommandDepth = 5
+
+// commandHelp is the parsed help text of a cobra-based command
+type commandHelp struct {
+	description string
+	usage       []string
+	commands    []subcommand
+	flags       []flagHelp
+}
+
+type subcommand struct {
+	name        string
+	description string
+}
+
+type flagHelp struct {
+	shorthand    string
+	name         string
+	valueType    string
+	defaultValue string
+	usage        string
+}
+
+var (
+	sectionPattern = regexp.MustCompile(`^(\S.*):$`)
+	flagPattern    = regexp.MustCompile(`^\s+(?:-(\S), )?--(\S+)(?: (\S+))?\s{2,}(.*)$`)
+	defaultPattern = regexp.MustCompile(`\s*\(default (.*)\)$`)
+)
+
+// printCommands walks the subcommands of a cobra-based command via --help,
+// and returns a Markdown reference which has one section per command
+func printCommands(option helpOption, cmd string, args ...string) (output string, err error) {
+	if err = option.checkCommand(cmd, args); err != nil {
+		return
+	}
+
+	builder := &strings.Builder{}
+	if err = walkCommands(builder, option, cmd, args, 0); err != nil {
+		if option.strict {
+			return
+		}
+		logger.Printf("failed to walk the subcommands of [%s], error: %v\n", cmd, err)
+		err = nil
+	}
+	output = strings.TrimRight(builder.String(), "\n")
+	return
+}
+
+func walkCommands(builder *strings.Builder, option helpOption, cmd string, args []string, depth int) (err error) {
+	var data []byte
+	if data, err = runHelp(option, cmd, args...); err != nil {
+		return
+	}
+	help := parseCommandHelp(cleanHelp(string(data)))
+
+	level := "##"
+	if depth > 0 {
+		level = "###"
+	}
+	writeCommandHelp(builder, level, strings.Join(append([]string{cmd}, args...), " "), help)
+
+	if depth >= maxCommandDepth {
+		return
+	}
+	for _, sub := range help.commands {
+		// the built-in commands of cobra are not a part of the reference
+		if sub.name == "help" || sub.name == "completion" {
+			continue
+		}
+		// the discovered subcommands come from the output of a command, they pass the same check as the given ones
+		subArgs := append(append([]string{}, args...), sub.name)
+		if err = option.checkCommand(cmd, subArgs); err != nil {
+			if option.strict {
+				return
+			}
+			logger.Printf("skip the subcommand [%s] of [%s], error: %v\n", sub.name, cmd, err)
+			err = nil
+			continue
+		}
+		if err = walkCommands(builder, option, cmd, subArgs, depth+1); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// parseCommandHelp parses the sections of the help text, the text before the usage is the description
+func parseCommandHelp(text string) (help commandHelp) {
+	var section string
+	var description []string
+	for _, line := range strings.Split(text, "\n") {
+		// the description might have a line which looks like a section
+		if match := sectionPattern.FindStringSubmatch(line); match != nil && (section != "" || match[1] == "Usage") {
+			section = match[1]
+			continue
+		}
+
+		switch {
+		case section == "":
+			description = append(description, line)
+		case section == "Usage":
+			if strings.TrimSpace(line) != "" {
+				help.usage = append(help.usage, strings.TrimSpace(line))
+			}
+		case strings.HasSuffix(section, "Commands"):
+			if fields := strings.Fields(line); len(fields) > 0 {
+				help.commands = append(help.commands, subcommand{
+					name:        fields[0],
+					description: strings.Join(fields[1:], " "),
+				})
+			}
+		case section == "Flags":
+			if match := flagPattern.FindStringSubmatch(line); match != nil {
+				flag := flagHelp{shorthand: match[1], name: match[2], valueType: match[3], usage: match[4]}
+				if defaultMatch := defaultPattern.FindStringSubmatch(flag.usage); defaultMatch != nil {
+					flag.defaultValue = defaultMatch[1]
+					flag.usage = strings.TrimSuffix(flag.usage, defaultMatch[0])
+				}
+				help.flags = append(help.flags, flag)
+			} else if count := len(help.flags); count > 0 && strings.HasPrefix(line, " ") && strings.TrimSpace(line) != "" {
+				// the usage of a flag could be in multiple lines
+				help.flags[count-1].usage += " " + strings.TrimSpace(line)
+			}
+		}
+	}
+	help.description = strings.TrimSpace(strings.Join(description, "\n"))
+	return
+}
+
+func writeCommandHelp(builder *strings.Builder, level, name string, help commandHelp) {
+	builder.WriteString(fmt.Sprintf("%s %s\n\n", level, name))
+	if help.description != "" {
+		builder.WriteString(help.description + "\n\n")
+	}
+	if len(help.usage) > 0 {
+		builder.WriteString(fmt.Sprintf("```shell\n%s\n```\n\n", strings.Join(help.usage, "\n")))
+	}
+	if len(help.flags) > 0 {
+		builder.WriteString("| Flag | Shorthand | Type | Default | Description |\n|---|---|---|---|---|\n")
+		for _, flag := range help.flags {
+			builder.WriteString(fmt.Sprintf("| `--%s` | %s | %s | %s | %s |\n", flag.name,
+				codeOrEmpty("-", flag.shorthand), codeOrEmpty("", flag.valueType),
+				codeOrEmpty("", flag.defaultValue), escapeTableCell(flag.usage)))
+		}
+		builder.WriteString("\n")
+	}
+}
+
+// codeOrEmpty returns the text as inline code, or empty if the text is empty
+func codeOrEmpty(prefix, text string) string {
+	if text == "" {
+		return ""
+	}
+	return "`" + prefix + escapeTableCell(text) + "`"
+}
+
+func escapeTableCell(text string) string {
+	return strings.ReplaceAll(text, "|", `\|`)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+const fakeCobraCLI = `#!/bin/sh
+case "$*" in
+"--help")
+cat <<'HELP'
+A fake CLI for testing.
+Note:
+It has subcommands.
+
+Usage:
+  fake [flags]
+  fake [command]
+
+Available Commands:
+  completion  Generate the autocompletion script for the specified shell
+  help        Help about any command
+  install     Install a package
+  run.sh      Run a script
+
+Flags:
+  -h, --help            help for fake
+  -o, --output string   The output format, json|yaml (default "json")
+
+Use "fake [command] --help" for more information about a command.
+HELP
+;;
+"install --help")
+cat <<'HELP'
+Install a package
+
+Usage:
+  fake install [flags]
+
+Flags:
+      --force   Overwrite the existing package
+                and its config
+  -h, --help    help for install
+
+Global Flags:
+  -o, --output string   The output format, json|yaml (default "json")
+HELP
+;;
+"run.sh --help")
+echo "Run a script"
+;;
+*)
+exit 1
+;;
+esac
+`
+
+func Test_printCommands(t *testing.T) {
+	dir := t.TempDir()
+	fakeCLI := filepath.Join(dir, "fake")
+	err := ioutil.WriteFile(fakeCLI, []byte(fakeCobraCLI), 0755)
+	assert.Nil(t, err)
+
+	output, err := printCommands(defaultHelpOption, fakeCLI)
+	assert.Nil(t, err)
+	assert.Equal(t, "## "+fakeCLI+`
+
+A fake CLI for testing.
+Note:
+It has subcommands.
+
+`+"```shell"+`
+fake [flags]
+fake [command]
+`+"```"+`
+
+| Flag | Shorthand | Type | Default | Description |
+|---|---|---|---|---|
+| `+"`--help` | `-h` |  |  | help for fake |"+`
+| `+"`--output` | `-o` | `string` | `\"json\"` | The output format, json\\|yaml |"+`
+
+### `+fakeCLI+` install
+
+Install a package
+
+`+"```shell"+`
+fake install [flags]
+`+"```"+`
+
+| Flag | Shorthand | Type | Default | Description |
+|---|---|---|---|---|
+| `+"`--force` |  |  |  | Overwrite the existing package and its config |"+`
+| `+"`--help` | `-h` |  |  | help for install |", output)
+
+	// a subcommand which fails
+	_, err = printCommands(helpOption{timeout: defaultHelpOption.timeout, strict: true, allowlist: []string{fakeCLI}}, fakeCLI, "fake")
+	assert.NotNil(t, err)
+
+	// the subcommands cannot be flags
+	_, err = printCommands(defaultHelpOption, "sh", "-c", "echo hello")
+	assert.NotNil(t, err)
+	_, err = printCommands(helpOption{timeout: defaultHelpOption.timeout, allowlist: []string{"sh"}}, "sh", "-c", "echo hello")
+	assert.NotNil(t, err)
+	output, err = printCommands(defaultHelpOption, fakeCLI, "install")
+	assert.Nil(t, err)
+	assert.Contains(t, output, "## "+fakeCLI+" install")
+
+	_, err = printCommands(helpOption{timeout: defaultHelpOption.timeout, allowlist: []string{"hd"}}, fakeCLI)
+	assert.NotNil(t, err)
+
+	// the discovered subcommands which do not look like names are skipped, or fail in the strict mode
+	output, err = printCommands(defaultHelpOption, fakeCLI)
+	assert.Nil(t, err)
+	assert.NotContains(t, output, "run.sh")
+	_, err = printCommands(helpOption{timeout: defaultHelpOption.timeout, strict: true}, fakeCLI)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"github.com/spf13/pflag"
+	"io/ioutil"
+	"strings"
+)
+
+// parseDirective returns the arguments of the directive line which looks like:
+// #!yaml-readme -p 'data/*.yaml' --layout base.tpl
+func parseDirective(tplContent string) (args []string) {
+	if !strings.HasPrefix(tplContent, "#!yaml-readme") {
+		return
+	}
+
+	line := strings.SplitN(tplContent, "\n", 2)[0]
+	args = splitArgs(strings.TrimPrefix(line, "#!yaml-readme"))
+	return
+}
+
+// splitArgs splits a command line into arguments, single and double quotes are supported
+func splitArgs(line string) (args []string) {
+	var (
+		current strings.Builder
+		quote   rune
+		inArg   bool
+	)
+	for _, c := range line {
+		switch {
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(c)
+		case c == '\'' || c == '"':
+			quote = c
+			inArg = true
+		case c == ' ' || c == '\t' || c == '\r':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(c)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return
+}
+
+// quoteArg quotes an argument with single quotes which could be split by splitArgs,
+// a single quote in the argument is written as '"'"'
+func quoteArg(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'"'"'`) + "'"
+}
+
+// directiveFlags are the flags which could be set in the directive, they only shape the template.
+// The others, such as the output, the header, or the ones which run commands, belong to the caller.
+var directiveFlags = []string{"layout", "delims", "pattern", "group-by", "sort-by", "template-dir"}
+
+// applyDirective takes the flags from the directive line of the template file,
+// the flags from the command line have a higher priority
+func applyDirective(flags *pflag.FlagSet, templateFile string) (err error) {
+	var data []byte
+	if data, err = ioutil.ReadFile(templateFile); err != nil {
+		// it's not the duty of the directive to report a missing template
+		err = nil
+		return
+	}
+
+	args := parseDirective(string(data))
+	if len(args) == 0 {
+		return
+	}
+
+	// parse the directive with a standalone flag set to keep the command line flags
+	templateFlags := newRootCommand().Flags()
+	templateFlags.ParseErrorsWhitelist.UnknownFlags = true
+	if err = templateFlags.Parse(args); err != nil {
+		return
+	}
+
+	templateFlags.Visit(func(flag *pflag.Flag) {
+		if err != nil || flags.Changed(flag.Name) {
+			return
+		}
+		if !contains(directiveFlags, flag.Name) {
+			logger.Printf("ignore the flag [--%s] in the directive of [%s], it could be set in the command line only\n",
+				flag.Name, templateFile)
+			return
+		}
+
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			err = flags.Lookup(flag.Name).Value.(pflag.SliceValue).Replace(slice.GetSlice())
+		} else {
+			err = flags.Set(flag.Name, flag.Value.String())
+		}
+	})
+	return
+}
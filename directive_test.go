@@ -0,0 +1,102 @@
+package main
+
+import (
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path"
+	"testing"
+)
+
+func Test_parseDirective(t *testing.T) {
+	tests := []struct {
+		name       string
+		tplContent string
+		wantArgs   []string
+	}{{
+		name:       "without directive",
+		tplContent: "a fake template",
+	}, {
+		name: "with quoted arguments",
+		tplContent: `#!yaml-readme -p 'data/financing/*.yaml' --output "financing page.md"
+a fake template`,
+		wantArgs: []string{"-p", "data/financing/*.yaml", "--output", "financing page.md"},
+	}, {
+		name:       "empty argument",
+		tplContent: `#!yaml-readme --layout ''`,
+		wantArgs:   []string{"--layout", ""},
+	}, {
+		name:       "quoted by quoteArg",
+		tplContent: "#!yaml-readme -p " + quoteArg(`data/it's "new"/*.yaml`),
+		wantArgs:   []string{"-p", `data/it's "new"/*.yaml`},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantArgs, parseDirective(tt.tplContent))
+		})
+	}
+}
+
+func Test_applyDirective(t *testing.T) {
+	untrusted := path.Join(t.TempDir(), "README.tpl")
+	assert.Nil(t, ioutil.WriteFile(untrusted, []byte("#!yaml-readme --layout base.tpl --output fake.md --allow-exec --strict\n"), 0644))
+
+	tests := []struct {
+		name         string
+		templateFile string
+		args         []string
+		verify       func(t *testing.T, opt *option)
+	}{{
+		name:         "take the flags from the directive",
+		templateFile: "function/data/layout/README.tpl",
+		verify: func(t *testing.T, opt *option) {
+			assert.Equal(t, "base.tpl", opt.layout)
+			assert.Equal(t, "function/data/*.yaml", opt.pattern)
+		},
+	}, {
+		name:         "command line flags have a higher priority",
+		templateFile: "function/data/layout/README.tpl",
+		args:         []string{"--pattern", "items/*.yaml"},
+		verify: func(t *testing.T, opt *option) {
+			assert.Equal(t, "base.tpl", opt.layout)
+			assert.Equal(t, "items/*.yaml", opt.pattern)
+		},
+	}, {
+		name:         "template without directive",
+		templateFile: "function/data/README.tpl",
+		verify: func(t *testing.T, opt *option) {
+			assert.Equal(t, "", opt.layout)
+			assert.Equal(t, "items/*.yaml", opt.pattern)
+		},
+	}, {
+		name:         "only the flags which shape the template",
+		templateFile: untrusted,
+		verify: func(t *testing.T, opt *option) {
+			assert.Equal(t, "base.tpl", opt.layout)
+			assert.Equal(t, "", opt.output)
+			assert.False(t, opt.allowExec)
+			assert.False(t, opt.strict)
+		},
+	}, {
+		name:         "template does not exist",
+		templateFile: "fake",
+		verify: func(t *testing.T, opt *option) {
+			assert.Equal(t, "items/*.yaml", opt.pattern)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opt := &option{}
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			flags.StringVarP(&opt.pattern, "pattern", "p", "items/*.yaml", "")
+			flags.StringVarP(&opt.layout, "layout", "", "", "")
+			flags.StringVarP(&opt.output, "output", "", "", "")
+			flags.BoolVarP(&opt.allowExec, "allow-exec", "", false, "")
+			flags.BoolVarP(&opt.strict, "strict", "", false, "")
+			assert.Nil(t, flags.Parse(tt.args))
+
+			assert.Nil(t, applyDirective(flags, tt.templateFile))
+			tt.verify(t, opt)
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// templateExecutor is the common part of text/template and html/template
+type templateExecutor interface {
+	Execute(wr io.Writer, data interface{}) error
+	ExecuteTemplate(wr io.Writer, name string, data interface{}) error
+}
+
+// getEngine returns the template engine, it's html for the HTML outputs and text for others by default
+func (o *option) getEngine() (engine string, err error) {
+	switch o.engine {
+	case "":
+		engine = "text"
+		switch strings.ToLower(filepath.Ext(o.output)) {
+		case ".html", ".htm":
+			engine = "html"
+		}
+	case "text", "html":
+		engine = o.engine
+	default:
+		err = fmt.Errorf("unsupported engine %q, it should be text or html", o.engine)
+	}
+	return
+}
+
+// getDelims returns the delimiters of the template, the empty ones stand for the default delimiters
+func (o *option) getDelims() (left, right string, err error) {
+	if o.delims == "" {
+		return
+	}
+
+	delims := strings.Split(o.delims, ",")
+	if len(delims) != 2 || strings.TrimSpace(delims[0]) == "" || strings.TrimSpace(delims[1]) == "" {
+		err = fmt.Errorf("invalid delimiters %q, it should be like '[[,]]'", o.delims)
+		return
+	}
+	left, right = strings.TrimSpace(delims[0]), strings.TrimSpace(delims[1])
+	return
+}
+
+// getMissingKeyOption returns the template option of the missing key
+func (o *option) getMissingKeyOption() (option string, err error) {
+	switch o.missingKey {
+	case "":
+		option = "missingkey=default"
+	case "error", "zero", "default":
+		option = "missingkey=" + o.missingKey
+	default:
+		err = fmt.Errorf("unsupported missing key option %q, it should be one of error, zero, default", o.missingKey)
+	}
+	return
+}
+
+var (
+	missingKeyPattern = regexp.MustCompile(`at <([^>]*)>: map has no entry for key "(.*)"`)
+	// fieldChainPattern matches the field chains like $val.meta.name or .name
+	fieldChainPattern = regexp.MustCompile(`^(?:\$\w*)?((?:\.\w+)+)$`)
+)
+
+// explainMissingKey adds the path of the first item which misses the key into the error,
+// the error keeps the template location only in case the key is not missing in an item
+func explainMissingKey(err error, object interface{}) error {
+	matches := missingKeyPattern.FindStringSubmatch(err.Error())
+	if matches == nil {
+		return err
+	}
+
+	chain := fieldChainPattern.FindStringSubmatch(matches[1])
+	if chain == nil {
+		return err
+	}
+	fields := strings.Split(strings.TrimPrefix(chain[1], "."), ".")
+	if fullpath := findItemWithoutKey(object, fields, matches[2]); fullpath != "" {
+		err = fmt.Errorf("%v, it is missing in the item %q", err, fullpath)
+	}
+	return err
+}
+
+// findItemWithoutKey returns the path of the first item which fails on the key when looking up the fields
+func findItemWithoutKey(object interface{}, fields []string, key string) (fullpath string) {
+	switch data := object.(type) {
+	case map[string]interface{}:
+		if missing, ok := missingField(data, fields); ok && missing == key {
+			fullpath, _ = data["fullpath"].(string)
+		}
+	case *page:
+		fullpath = findItemWithoutKey(data.Items, fields, key)
+	case []map[string]interface{}:
+		for _, item := range data {
+			if fullpath = findItemWithoutKey(item, fields, key); fullpath != "" {
+				break
+			}
+		}
+	case map[string][]map[string]interface{}:
+		// keep the same order with the template
+		var groups []string
+		for group := range data {
+			groups = append(groups, group)
+		}
+		sort.Strings(groups)
+		for _, group := range groups {
+			if fullpath = findItemWithoutKey(data[group], fields, key); fullpath != "" {
+				break
+			}
+		}
+	}
+	return
+}
+
+// missingField returns the first field which is missing when looking up the fields in the nested maps
+func missingField(value interface{}, fields []string) (missing string, ok bool) {
+	for _, field := range fields {
+		var found bool
+		switch data := value.(type) {
+		case map[string]interface{}:
+			value, found = data[field]
+		case map[interface{}]interface{}:
+			value, found = data[field]
+		default:
+			return
+		}
+		if !found {
+			return field, true
+		}
+	}
+	return
+}
+
+// toHTMLTemplate turns the parsed text templates into an HTML one which escapes the values
+func toHTMLTemplate(tpl *template.Template, funcMap template.FuncMap, options ...string) (htmlTpl *htmltemplate.Template, err error) {
+	htmlTpl = htmltemplate.New(tpl.Name()).Funcs(htmltemplate.FuncMap(funcMap)).Option(options...)
+	for _, item := range tpl.Templates() {
+		if item.Tree == nil {
+			continue
+		}
+		if _, err = htmlTpl.AddParseTree(item.Name(), item.Tree); err != nil {
+			return
+		}
+	}
+	// the added tree is not bound to the existing template
+	htmlTpl = htmlTpl.Lookup(tpl.Name())
+	return
+}
+
+var htmlType = reflect.TypeOf(htmltemplate.HTML(""))
+
+// renderedFuncs render a template by the html engine, so the output is escaped already
+var renderedFuncs = []string{"include", "renderFile", "printToc"}
+
+// markupFuncs are the built-in functions which build their own Markdown or HTML, the arguments are escaped instead of the output.
+// The output of the others, such as render, readFile, snippet, and exec, comes from the data or files, so it is escaped.
+var markupFuncs = []string{"printContributors", "printStarHistory", "printVisitorCount", "printPages", "printGHTable",
+	"gh", "ghs", "ghEmoji", "link", "linkOrEmpty", "twitterLink", "youTubeLink"}
+
+// trustFuncs marks the string output of the rendered and markup functions as safe HTML, so it is not escaped by the html engine
+func trustFuncs(funcMap template.FuncMap) (trusted template.FuncMap) {
+	trusted = template.FuncMap{}
+	for name, fn := range funcMap {
+		if contains(renderedFuncs, name) {
+			fn = trustFunc(fn, false)
+		} else if contains(markupFuncs, name) {
+			fn = trustFunc(fn, true)
+		}
+		trusted[name] = fn
+	}
+	return
+}
+
+// trustFunc marks the string output of a function as safe HTML, and escapes the string arguments if escapeArgs is true
+func trustFunc(fn interface{}, escapeArgs bool) interface{} {
+	fnType := reflect.TypeOf(fn)
+	if fnType.Kind() != reflect.Func || fnType.NumOut() == 0 || fnType.Out(0).Kind() != reflect.String {
+		return fn
+	}
+
+	in := make([]reflect.Type, fnType.NumIn())
+	for i := range in {
+		in[i] = fnType.In(i)
+	}
+	out := make([]reflect.Type, fnType.NumOut())
+	for i := range out {
+		out[i] = fnType.Out(i)
+	}
+	out[0] = htmlType
+
+	fnValue := reflect.ValueOf(fn)
+	return reflect.MakeFunc(reflect.FuncOf(in, out, fnType.IsVariadic()), func(args []reflect.Value) (results []reflect.Value) {
+		if escapeArgs {
+			args = escapeValues(args)
+		}
+		if fnType.IsVariadic() {
+			results = fnValue.CallSlice(args)
+		} else {
+			results = fnValue.Call(args)
+		}
+		results[0] = results[0].Convert(htmlType)
+		return
+	}).Interface()
+}
+
+// escapeValues escapes the strings and the string slices, the variadic arguments are a slice
+func escapeValues(values []reflect.Value) (escaped []reflect.Value) {
+	escaped = make([]reflect.Value, len(values))
+	for i, value := range values {
+		switch {
+		case value.Kind() == reflect.String:
+			value = reflect.ValueOf(htmltemplate.HTMLEscapeString(value.String())).Convert(value.Type())
+		case value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.String:
+			value = reflect.AppendSlice(reflect.MakeSlice(value.Type(), 0, value.Len()), value)
+			for j := 0; j < value.Len(); j++ {
+				value.Index(j).SetString(htmltemplate.HTMLEscapeString(value.Index(j).String()))
+			}
+		}
+		escaped[i] = value
+	}
+	return
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	htmltemplate "html/template"
+	"testing"
+)
+
+func Test_getEngine(t *testing.T) {
+	tests := []struct {
+		name       string
+		opt        *option
+		wantEngine string
+		wantErr    bool
+	}{{
+		name:       "stdout",
+		opt:        &option{},
+		wantEngine: "text",
+	}, {
+		name:       "Markdown output",
+		opt:        &option{output: "README.md"},
+		wantEngine: "text",
+	}, {
+		name:       "HTML output",
+		opt:        &option{output: "index.HTML"},
+		wantEngine: "html",
+	}, {
+		name:       "specific engine",
+		opt:        &option{output: "index.html", engine: "text"},
+		wantEngine: "text",
+	}, {
+		name:    "unknown engine",
+		opt:     &option{engine: "fake"},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, err := tt.opt.getEngine()
+			assert.Equal(t, tt.wantEngine, engine)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func Test_renderTemplate_engine(t *testing.T) {
+	tests := []struct {
+		name       string
+		engine     string
+		tplContent string
+		object     interface{}
+		wantOutput string
+	}{{
+		name:       "text engine keeps the values",
+		engine:     "text",
+		tplContent: `{{.name}} {{link .name "https://github.com"}}`,
+		object:     map[string]string{"name": `<a & "b">`},
+		wantOutput: `<a & "b"> [<a & "b">](https://github.com)`,
+	}, {
+		name:       "html engine escapes the values, and the arguments of the markup functions",
+		engine:     "html",
+		tplContent: `{{.name}} {{link .name "https://github.com"}} {{upper .name}} {{printVisitorCount "<b>"}}`,
+		object:     map[string]string{"name": `<a & "b">`},
+		wantOutput: `&lt;a &amp; &#34;b&#34;&gt; [&lt;a &amp; &#34;b&#34;&gt;](https://github.com) &lt;A &amp; &#34;B&#34;&gt; ` +
+			`![Visitor Count](https://profile-counter.glitch.me/&lt;b&gt;/count.svg)`,
+	}, {
+		name:       "html engine escapes the output of the functions which do not build markup",
+		engine:     "html",
+		tplContent: `{{render .name}} {{readFile "function/data/item.tpl"}}`,
+		object:     map[string]string{"name": `<script>`},
+		wantOutput: "&lt;script&gt; # {{.zh}}\n\n{{.en}}",
+	}, {
+		name:       "html engine does not escape the included template twice",
+		engine:     "html",
+		tplContent: `{{define "row"}}<td>{{.}}</td>{{end}}{{include "row" "<b>"}}`,
+		wantOutput: `<td>&lt;b&gt;</td>`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := (&option{engine: tt.engine, templateFile: "README.tpl"}).renderTemplateToString(tt.tplContent, tt.object)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantOutput, output)
+		})
+	}
+}
+
+func Test_trustFunc(t *testing.T) {
+	// variadic function
+	fn := trustFunc(func(items ...string) string {
+		return items[0]
+	}, false).(func(...string) htmltemplate.HTML)
+	assert.Equal(t, htmltemplate.HTML("<b>"), fn("<b>", "fake"))
+
+	// escape the arguments, and keep the markup
+	items := []string{"<b>", "fake"}
+	fn = trustFunc(func(items ...string) string {
+		return "<i>" + items[0] + "</i>"
+	}, true).(func(...string) htmltemplate.HTML)
+	assert.Equal(t, htmltemplate.HTML("<i>&lt;b&gt;</i>"), fn(items...))
+	assert.Equal(t, []string{"<b>", "fake"}, items)
+	link := trustFunc(func(text, link string) string {
+		return "<a href=\"" + link + "\">" + text + "</a>"
+	}, true).(func(string, string) htmltemplate.HTML)
+	assert.Equal(t, htmltemplate.HTML(`<a href="&#34;x">&lt;b&gt;</a>`), link("<b>", `"x`))
+
+	// keep the functions which do not return a string
+	number := func() int {
+		return 1
+	}
+	assert.Equal(t, 1, trustFunc(number, true).(func() int)())
+}
+
+func Test_getDelims(t *testing.T) {
+	tests := []struct {
+		name      string
+		delims    string
+		wantLeft  string
+		wantRight string
+		wantErr   bool
+	}{{
+		name: "default delimiters",
+	}, {
+		name:      "custom delimiters",
+		delims:    "[[, ]]",
+		wantLeft:  "[[",
+		wantRight: "]]",
+	}, {
+		name:    "only one delimiter",
+		delims:  "[[",
+		wantErr: true,
+	}, {
+		name:    "empty delimiter",
+		delims:  "[[,",
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			left, right, err := (&option{delims: tt.delims}).getDelims()
+			assert.Equal(t, tt.wantLeft, left)
+			assert.Equal(t, tt.wantRight, right)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func Test_renderTemplate_missingKey(t *testing.T) {
+	items := []map[string]interface{}{{
+		"name": "rick", "fullpath": "items/rick.yaml",
+	}, {
+		"nmae": "linuxsuren", "fullpath": "items/linuxsuren.yaml",
+	}}
+	tpl := `{{range $val := .}}
+{{$val.nmae}}
+{{- end}}`
+	tests := []struct {
+		name       string
+		missingKey string
+		tpl        string
+		object     interface{}
+		wantOutput string
+		wantErr    []string
+		notWantErr string
+	}{{
+		name:       "default behavior",
+		missingKey: "default",
+		object:     items,
+		wantOutput: "\n<no value>\nlinuxsuren",
+	}, {
+		name:       "report the missing key",
+		missingKey: "error",
+		object:     items,
+		wantErr:    []string{"readme:2:6", `"nmae"`, `"items/rick.yaml"`},
+	}, {
+		name:       "report the missing key in grouped data",
+		missingKey: "error",
+		object: map[string][]map[string]interface{}{
+			"b": {items[0]},
+			"a": {items[1]},
+		},
+		wantErr: []string{`"items/rick.yaml"`},
+	}, {
+		name:       "report the missing key in nested data",
+		missingKey: "error",
+		tpl: `{{range $val := .}}
+{{$val.meta.nmae}}
+{{- end}}`,
+		object: []map[string]interface{}{
+			{"meta": map[interface{}]interface{}{"nmae": "hd"}, "fullpath": "items/hd.yaml"},
+			{"meta": map[interface{}]interface{}{"name": "rick"}, "fullpath": "items/rick.yaml"},
+		},
+		wantErr:    []string{"readme:2:6", `"nmae"`, `"items/rick.yaml"`},
+		notWantErr: "items/hd.yaml",
+	}, {
+		name:       "report the missing parent key in nested data",
+		missingKey: "error",
+		tpl: `{{range $val := .}}
+{{$val.meta.nmae}}
+{{- end}}`,
+		object:  []map[string]interface{}{{"fullpath": "items/hd.yaml"}},
+		wantErr: []string{`"meta"`, `"items/hd.yaml"`},
+	}, {
+		name:       "report the missing key without an item",
+		missingKey: "error",
+		tpl:        `{{range $val := .}}{{(dict "name" $val.name).nmae}}{{end}}`,
+		object:     items,
+		wantErr:    []string{"readme:1:", `"nmae"`},
+		notWantErr: "items/rick.yaml",
+	}, {
+		name:       "unknown option",
+		missingKey: "fake",
+		object:     items,
+		wantErr:    []string{"unsupported missing key option"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groupTpl := tpl
+			if tt.tpl != "" {
+				groupTpl = tt.tpl
+			} else if _, ok := tt.object.(map[string][]map[string]interface{}); ok {
+				groupTpl = `{{range $key, $val := .}}` + tpl + `{{end}}`
+			}
+
+			output, err := (&option{missingKey: tt.missingKey}).renderTemplateToString(groupTpl, tt.object)
+			if len(tt.wantErr) > 0 {
+				if assert.NotNil(t, err) {
+					for _, msg := range tt.wantErr {
+						assert.Contains(t, err.Error(), msg)
+					}
+					if tt.notWantErr != "" {
+						assert.NotContains(t, err.Error(), tt.notWantErr)
+					}
+				}
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.wantOutput, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"regexp"
+)
+
+var envPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// expandEnv expands ${VAR} and ${VAR:-default} in all the string values of the data,
+// only the variables in the allowlist will be expanded, others keep as they are
+func expandEnv(data interface{}, allowlist []string) interface{} {
+	switch val := data.(type) {
+	case string:
+		return expandEnvString(val, allowlist)
+	case map[string]interface{}:
+		for k, v := range val {
+			val[k] = expandEnv(v, allowlist)
+		}
+	case map[interface{}]interface{}:
+		for k, v := range val {
+			val[k] = expandEnv(v, allowlist)
+		}
+	case []interface{}:
+		for i, v := range val {
+			val[i] = expandEnv(v, allowlist)
+		}
+	}
+	return data
+}
+
+func expandEnvString(text string, allowlist []string) string {
+	return envPattern.ReplaceAllStringFunc(text, func(expr string) string {
+		groups := envPattern.FindStringSubmatch(expr)
+		name, hasDefault, defaultVal := groups[1], groups[2] != "", groups[3]
+		if !contains(allowlist, name) {
+			return expr
+		}
+
+		if val, ok := os.LookupEnv(name); ok && (val != "" || !hasDefault) {
+			return val
+		}
+		return defaultVal
+	})
+}
+
+func contains(items []string, target string) bool {
+	for _, item := range items {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_expandEnv(t *testing.T) {
+	t.Setenv("VERSION", "v0.0.1")
+	t.Setenv("EMPTY", "")
+	t.Setenv("GITHUB_TOKEN", "secret")
+
+	allowlist := []string{"VERSION", "EMPTY", "REGISTRY"}
+	tests := []struct {
+		name string
+		data interface{}
+		want interface{}
+	}{{
+		name: "simple variable",
+		data: "version: ${VERSION}",
+		want: "version: v0.0.1",
+	}, {
+		name: "default value of an unset variable",
+		data: "${REGISTRY:-docker.io}/linuxsuren/yaml-readme:${VERSION}",
+		want: "docker.io/linuxsuren/yaml-readme:v0.0.1",
+	}, {
+		name: "default value of an empty variable",
+		data: "${EMPTY:-none}",
+		want: "none",
+	}, {
+		name: "unset variable without default value",
+		data: "[${REGISTRY}]",
+		want: "[]",
+	}, {
+		name: "variable is not in the allowlist",
+		data: "${GITHUB_TOKEN} ${GITHUB_TOKEN:-fake}",
+		want: "${GITHUB_TOKEN} ${GITHUB_TOKEN:-fake}",
+	}, {
+		name: "nested values",
+		data: map[string]interface{}{
+			"name":  "yaml-readme",
+			"year":  2022,
+			"tags":  []interface{}{"${VERSION}", 1},
+			"image": map[interface{}]interface{}{"tag": "${VERSION}"},
+		},
+		want: map[string]interface{}{
+			"name":  "yaml-readme",
+			"year":  2022,
+			"tags":  []interface{}{"v0.0.1", 1},
+			"image": map[interface{}]interface{}{"tag": "v0.0.1"},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, expandEnv(tt.data, allowlist))
+		})
+	}
+}
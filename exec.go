@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultExecTimeout is the default timeout of the exec function
+const defaultExecTimeout = 30 * time.Second
+
+var errExecDisabled = errors.New("the exec function is disabled, please enable it via --allow-exec")
+
+// execCommand is a command to run with a timeout
+type execCommand struct {
+	name    string
+	args    []string
+	dir     string
+	env     []string
+	timeout time.Duration
+}
+
+// run runs the command and returns the stdout, the stderr is a part of the error if it fails
+func (c execCommand) run() (data []byte, err error) {
+	if _, err = exec.LookPath(c.name); err != nil {
+		err = fmt.Errorf("command [%s] is missing, error: %v", c.name, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	command := exec.CommandContext(ctx, c.name, c.args...)
+	command.Dir = c.dir
+	if len(c.env) > 0 {
+		command.Env = append(os.Environ(), c.env...)
+	}
+	data, err = command.Output()
+
+	var exitErr *exec.ExitError
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("command [%s] timed out after %v", c, c.timeout)
+	} else if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		err = fmt.Errorf("failed to run command [%s], error: %v, stderr: %s", c, err, bytes.TrimSpace(exitErr.Stderr))
+	}
+	return
+}
+
+// String returns the command line
+func (c execCommand) String() string {
+	return strings.Join(append([]string{c.name}, c.args...), " ")
+}
+
+// execCache keeps the results of the commands in one render, so a command in a loop runs only once
+type execCache struct {
+	lock    sync.Mutex
+	results map[string]execResult
+}
+
+type execResult struct {
+	output string
+	err    error
+}
+
+// commandRunner runs the commands of the exec functions in the directory of the template file
+type commandRunner struct {
+	timeout time.Duration
+	scope   fileScope
+	cache   *execCache
+}
+
+// getCommandRunner returns the runner of the exec functions, the results are shared by all the templates of one render
+func (o *option) getCommandRunner() (runner *commandRunner, err error) {
+	timeout := o.execTimeout
+	if timeout == 0 {
+		timeout = defaultExecTimeout
+	} else if timeout < 0 {
+		err = fmt.Errorf("invalid exec timeout %v, it should be positive", timeout)
+		return
+	}
+
+	if o.execCache == nil {
+		o.execCache = &execCache{results: map[string]execResult{}}
+	}
+	runner = &commandRunner{timeout: timeout, scope: newFileScope(o.templateFile), cache: o.execCache}
+	return
+}
+
+// exec runs a command with the args, and returns the stdout without the trailing newlines
+func (r *commandRunner) exec(cmd string, args ...string) (string, error) {
+	return r.execWith(nil, cmd, args...)
+}
+
+// execWith runs a command with the options which could have dir, env, and timeout. For example:
+// {{execWith (dict "dir" "docs" "env" (dict "LANG" "C") "timeout" "1m") "make" "help"}}
+func (r *commandRunner) execWith(options interface{}, cmd string, args ...string) (output string, err error) {
+	command := execCommand{name: cmd, args: args, dir: r.scope.dir, timeout: r.timeout}
+	if err = r.applyOptions(&command, options); err != nil {
+		return
+	}
+
+	key := fmt.Sprintf("%s\x00%s\x00%q\x00%q\x00%v", command.dir, command.name, command.args, command.env, command.timeout)
+	r.cache.lock.Lock()
+	defer r.cache.lock.Unlock()
+	if result, ok := r.cache.results[key]; ok {
+		return result.output, result.err
+	}
+
+	var data []byte
+	data, err = command.run()
+	output = strings.TrimRight(string(data), "\r\n")
+	r.cache.results[key] = execResult{output: output, err: err}
+	return
+}
+
+func (r *commandRunner) applyOptions(command *execCommand, options interface{}) (err error) {
+	if options == nil {
+		return
+	}
+	optionMap, ok := options.(map[string]interface{})
+	if !ok {
+		err = fmt.Errorf("the options of exec should be a dict, but it is %T", options)
+		return
+	}
+
+	for key, val := range optionMap {
+		switch key {
+		case "dir":
+			command.dir, err = r.scope.resolve(fmt.Sprint(val))
+		case "env":
+			command.env, err = toEnv(val)
+		case "timeout":
+			command.timeout, err = time.ParseDuration(fmt.Sprint(val))
+			if err == nil && command.timeout <= 0 {
+				err = fmt.Errorf("invalid timeout %v, it should be positive", command.timeout)
+			}
+		default:
+			err = fmt.Errorf("unsupported option %q of exec, it should be one of dir, env, timeout", key)
+		}
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// toEnv turns a dict or a list of KEY=VALUE into the environment variables
+func toEnv(val interface{}) (env []string, err error) {
+	switch items := val.(type) {
+	case map[string]interface{}:
+		for key, item := range items {
+			env = append(env, fmt.Sprintf("%s=%v", key, item))
+		}
+		sort.Strings(env)
+	case []interface{}:
+		for _, item := range items {
+			env = append(env, fmt.Sprint(item))
+		}
+	case []string:
+		env = items
+	default:
+		err = fmt.Errorf("the env of exec should be a dict or a list, but it is %T", val)
+	}
+	return
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_exec(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "docs"), 0755))
+	realDir, _ := filepath.EvalSymlinks(dir)
+
+	tests := []struct {
+		name     string
+		opt      *option
+		tpl      string
+		data     interface{}
+		expect   string
+		hasError bool
+	}{{
+		name:     "disabled by default",
+		opt:      &option{templateFile: filepath.Join(dir, "README.tpl")},
+		tpl:      `{{exec "echo" "hello"}}`,
+		hasError: true,
+	}, {
+		name:   "stdout without the trailing newlines",
+		opt:    &option{templateFile: filepath.Join(dir, "README.tpl"), allowExec: true},
+		tpl:    `{{exec "echo" "hello"}}!`,
+		expect: "hello!",
+	}, {
+		name:   "cached in one render",
+		opt:    &option{templateFile: filepath.Join(dir, "README.tpl"), allowExec: true},
+		tpl:    `{{range .}}{{exec "sh" "-c" "echo x >> count.txt; wc -l < count.txt | tr -d ' '"}}{{end}}`,
+		data:   []int{1, 2, 3},
+		expect: "111",
+	}, {
+		name:   "with dir and env",
+		opt:    &option{templateFile: filepath.Join(dir, "README.tpl"), allowExec: true},
+		tpl:    `{{execWith (dict "dir" "docs" "env" (dict "NAME" "yaml-readme")) "sh" "-c" "echo $NAME; pwd -P"}}`,
+		expect: "yaml-readme\n" + filepath.Join(realDir, "docs"),
+	}, {
+		name:   "with a list of env",
+		opt:    &option{templateFile: filepath.Join(dir, "README.tpl"), allowExec: true},
+		tpl:    `{{execWith (dict "env" (list "NAME=hd")) "sh" "-c" "echo $NAME"}}`,
+		expect: "hd",
+	}, {
+		name:     "timeout",
+		opt:      &option{templateFile: filepath.Join(dir, "README.tpl"), allowExec: true, execTimeout: 100 * time.Millisecond},
+		tpl:      `{{exec "sleep" "5"}}`,
+		hasError: true,
+	}, {
+		name:     "dir is out of the repository",
+		opt:      &option{templateFile: filepath.Join(dir, "README.tpl"), allowExec: true},
+		tpl:      `{{execWith (dict "dir" "..") "pwd"}}`,
+		hasError: true,
+	}, {
+		name:     "unknown option",
+		opt:      &option{templateFile: filepath.Join(dir, "README.tpl"), allowExec: true},
+		tpl:      `{{execWith (dict "fake" "fake") "pwd"}}`,
+		hasError: true,
+	}, {
+		name:     "command fails",
+		opt:      &option{templateFile: filepath.Join(dir, "README.tpl"), allowExec: true},
+		tpl:      `{{exec "sh" "-c" "echo bad >&2; exit 1"}}`,
+		hasError: true,
+	}, {
+		name:     "command is missing",
+		opt:      &option{templateFile: filepath.Join(dir, "README.tpl"), allowExec: true},
+		tpl:      `{{exec "fake-command-of-yaml-readme"}}`,
+		hasError: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := tt.opt.renderTemplateToString(tt.tpl, tt.data)
+			if tt.hasError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expect, output)
+			}
+		})
+	}
+}
+
+func Test_execStderr(t *testing.T) {
+	_, err := execCommand{name: "sh", args: []string{"-c", "echo bad >&2; exit 1"}, timeout: time.Second}.run()
+	assert.NotNil(t, err)
+	assert.True(t, strings.HasSuffix(err.Error(), "stderr: bad"), err.Error())
+}
+
+func Test_allowExecInDirective(t *testing.T) {
+	dir := t.TempDir()
+	templateFile := filepath.Join(dir, "README.tpl")
+	err := ioutil.WriteFile(templateFile, []byte("#!yaml-readme --allow-exec\n{{exec \"echo\" \"hello\"}}"), 0644)
+	assert.Nil(t, err)
+
+	// a template cannot allow itself to run commands
+	cmd := newRootCommand()
+	cmd.SetOut(bytes.NewBuffer([]byte{}))
+	cmd.SetArgs([]string{"--template", templateFile, "--pattern", "function/data/*.yaml"})
+	assert.NotNil(t, cmd.Execute())
+
+	buf := bytes.NewBuffer([]byte{})
+	cmd = newRootCommand()
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"--template", templateFile, "--pattern", "function/data/*.yaml", "--allow-exec", "--include-header=false"})
+	assert.Nil(t, cmd.Execute())
+	assert.Equal(t, "hello", buf.String())
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxRenderDepth limits the depth of renderFile in case a template renders itself
+const maxRenderDepth = 10
+
+// fileScope resolves the paths which are relative to the template file, and keeps them in the repository
+type fileScope struct {
+	// root is the repository root, the files out of it are not allowed
+	root string
+	// dir is the directory of the template file
+	dir string
+}
+
+// newFileScope returns the scope of a template file, the repository root is the nearest directory which has .git,
+// or the directory of the template file if there is none
+func newFileScope(templateFile string) (scope fileScope) {
+	scope.dir = filepath.Dir(templateFile)
+
+	dir, err := filepath.Abs(scope.dir)
+	if err == nil {
+		dir, err = filepath.EvalSymlinks(dir)
+	}
+	if err != nil {
+		scope.root = scope.dir
+		return
+	}
+
+	for current := dir; ; current = filepath.Dir(current) {
+		if _, err = os.Stat(filepath.Join(current, ".git")); err == nil {
+			scope.root = current
+			return
+		}
+		if filepath.Dir(current) == current {
+			break
+		}
+	}
+	scope.root = dir
+	return
+}
+
+// resolve returns the real path of a file, it fails if the file is out of the repository root
+func (s fileScope) resolve(path string) (resolved string, err error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(s.dir, path)
+	}
+	if resolved, err = filepath.Abs(path); err != nil {
+		return
+	}
+	// the symbolic links might point to the files out of the repository
+	if resolved, err = filepath.EvalSymlinks(resolved); err != nil {
+		return
+	}
+
+	var root, rel string
+	if root, err = filepath.Abs(s.root); err == nil {
+		if root, err = filepath.EvalSymlinks(root); err == nil {
+			rel, err = filepath.Rel(root, resolved)
+		}
+	}
+	if err == nil && (rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))) {
+		err = fmt.Errorf("the file %q is out of the repository root %q", path, root)
+	}
+	return
+}
+
+// readFile returns the content of a file in the scope
+func (s fileScope) readFile(path string) (content string, err error) {
+	var data []byte
+	if path, err = s.resolve(path); err == nil {
+		if data, err = ioutil.ReadFile(path); err == nil {
+			content = string(data)
+		}
+	}
+	return
+}
+
+// includeMarkdown returns the content of a Markdown file without the front matter, and shifts the levels of its headings
+func (s fileScope) includeMarkdown(path string, shift int) (content string, err error) {
+	if content, err = s.readFile(path); err == nil {
+		_, content = splitFrontMatter(content)
+		content = shiftHeadings(content, shift)
+	}
+	return
+}
+
+// shiftHeadings shifts the levels of the ATX and setext headings which are not in the fenced code blocks,
+// the levels are kept between 1 and 6. The setext headings become ATX ones when they are shifted.
+func shiftHeadings(content string, shift int) string {
+	if shift == 0 {
+		return content
+	}
+
+	lines := strings.Split(content, "\n")
+	var fence string
+	previous := -1
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if fence != "" {
+			if strings.HasPrefix(strings.TrimSpace(line), fence) && strings.Trim(strings.TrimSpace(line), fence[:1]) == "" {
+				fence = ""
+			}
+			continue
+		}
+		if match := fencePattern.FindStringSubmatch(line); match != nil {
+			fence, previous = match[1], -1
+			continue
+		}
+
+		if match := atxHeadingPattern.FindStringSubmatch(line); match != nil {
+			lines[i] = shiftHeading(len(match[1]), shift, match[2])
+			previous = -1
+			continue
+		}
+		if match := setextHeadingPattern.FindStringSubmatch(line); match != nil && previous >= 0 {
+			level := 1
+			if match[1][0] == '-' {
+				level = 2
+			}
+			lines[previous], lines[i] = shiftHeading(level, shift, strings.TrimSpace(lines[previous])), ""
+			previous = -1
+			continue
+		}
+
+		previous = -1
+		if strings.TrimSpace(line) != "" && !blockPattern.MatchString(line) && !strings.HasPrefix(line, "    ") {
+			previous = i
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func shiftHeading(level, shift int, text string) string {
+	level += shift
+	if level < 1 {
+		level = 1
+	} else if level > 6 {
+		level = 6
+	}
+	return strings.TrimSpace(strings.Repeat("#", level) + " " + text)
+}
+
+// renderFile renders a template file with the data, it has the same functions and partials as the current template
+func (o *option) renderFile(path string, data interface{}) (output string, err error) {
+	if o.renderDepth >= maxRenderDepth {
+		err = fmt.Errorf("failed to render %q, the depth of renderFile is more than %d", path, maxRenderDepth)
+		return
+	}
+
+	var content string
+	scope := newFileScope(o.templateFile)
+	if path, err = scope.resolve(path); err == nil {
+		content, err = scope.readFile(path)
+	}
+	if err != nil {
+		return
+	}
+
+	// the file is rendered without the layout, and the paths in it are relative to itself
+	sub := *o
+	sub.layoutTpl = ""
+	sub.templateFile = path
+	sub.renderDepth++
+	return sub.renderTemplateToString(removeDirective(content), data)
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_fileScope(t *testing.T) {
+	outside := t.TempDir()
+	err := ioutil.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0644)
+	assert.Nil(t, err)
+
+	root := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(root, ".git"), 0755))
+	assert.Nil(t, os.MkdirAll(filepath.Join(root, "docs"), 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(root, "LICENSE"), []byte("MIT"), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(root, "docs", "usage.txt"), []byte("usage"), 0644))
+	assert.Nil(t, os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(root, "docs", "link.txt")))
+
+	scope := newFileScope(filepath.Join(root, "docs", "README.tpl"))
+	realRoot, _ := filepath.EvalSymlinks(root)
+	assert.Equal(t, realRoot, scope.root)
+
+	tests := []struct {
+		name     string
+		path     string
+		expect   string
+		hasError bool
+	}{{
+		name:   "relative to the template file",
+		path:   "usage.txt",
+		expect: "usage",
+	}, {
+		name:   "in the parent directory",
+		path:   "../LICENSE",
+		expect: "MIT",
+	}, {
+		name:     "out of the repository",
+		path:     "../../" + filepath.Base(outside) + "/secret.txt",
+		hasError: true,
+	}, {
+		name:     "absolute path out of the repository",
+		path:     filepath.Join(outside, "secret.txt"),
+		hasError: true,
+	}, {
+		name:     "symbolic link to the file out of the repository",
+		path:     "link.txt",
+		hasError: true,
+	}, {
+		name:     "file does not exist",
+		path:     "fake.txt",
+		hasError: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := scope.readFile(tt.path)
+			if tt.hasError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expect, content)
+			}
+		})
+	}
+}
+
+func Test_newFileScope(t *testing.T) {
+	dir := t.TempDir()
+	realDir, _ := filepath.EvalSymlinks(dir)
+
+	// the directory of the template file is the root if there is no repository
+	assert.Equal(t, fileScope{root: realDir, dir: dir}, newFileScope(filepath.Join(dir, "README.tpl")))
+}
+
+func Test_shiftHeadings(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		shift   int
+		expect  string
+	}{{
+		name:    "no shift",
+		content: "# Title",
+		expect:  "# Title",
+	}, {
+		name:    "ATX headings",
+		content: "# Title\ncontent\n## Install ##",
+		shift:   1,
+		expect:  "## Title\ncontent\n### Install",
+	}, {
+		name:    "setext headings",
+		content: "Title\n=====\n\nInstall\n---",
+		shift:   2,
+		expect:  "### Title\n\n\n#### Install\n",
+	}, {
+		name:    "fenced code blocks",
+		content: "# Title\n```shell\n# comment\n```",
+		shift:   1,
+		expect:  "## Title\n```shell\n# comment\n```",
+	}, {
+		name:    "keep the levels between 1 and 6",
+		content: "# Title\n###### Details",
+		shift:   -1,
+		expect:  "# Title\n##### Details",
+	}, {
+		name:    "the deepest level",
+		content: "##### Details",
+		shift:   3,
+		expect:  "###### Details",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, shiftHeadings(tt.content, tt.shift))
+		})
+	}
+}
+
+func Test_includeMarkdown(t *testing.T) {
+	dir := t.TempDir()
+	err := ioutil.WriteFile(filepath.Join(dir, "install.md"), []byte("---\ntitle: Install\n---\n# Install\nRun it."), 0644)
+	assert.Nil(t, err)
+
+	content, err := newFileScope(filepath.Join(dir, "README.tpl")).includeMarkdown("install.md", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "## Install\nRun it.", content)
+}
+
+func Test_renderFile(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "docs"), 0755))
+	err := ioutil.WriteFile(filepath.Join(dir, "docs", "item.tpl"),
+		[]byte(`#!yaml-readme --layout fake.tpl
+{{.name | upper}} {{readFile "version.txt"}}`), 0644)
+	assert.Nil(t, err)
+	err = ioutil.WriteFile(filepath.Join(dir, "docs", "version.txt"), []byte("v1"), 0644)
+	assert.Nil(t, err)
+	err = ioutil.WriteFile(filepath.Join(dir, "self.tpl"), []byte(`{{renderFile "self.tpl" .}}`), 0644)
+	assert.Nil(t, err)
+
+	opt := &option{templateFile: filepath.Join(dir, "README.tpl"), layoutTpl: `{{block "content" .}}{{end}} layout`}
+	output, err := opt.renderFile("docs/item.tpl", map[string]string{"name": "yaml-readme"})
+	assert.Nil(t, err)
+	assert.Equal(t, "YAML-README v1", output)
+
+	output, err = opt.renderTemplateToString(`{{renderFile "docs/item.tpl" .}}`, map[string]string{"name": "hd"})
+	assert.Nil(t, err)
+	assert.Equal(t, "HD v1 layout", output)
+
+	_, err = opt.renderFile("self.tpl", nil)
+	assert.NotNil(t, err)
+
+	_, err = opt.renderFile("../fake.tpl", nil)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,10 @@
+#!yaml-readme --delims '[[,]]'
+[[printToc]]
+## Usage
+```yaml
+steps:
+  - run: echo ${{ github.sha }}
+```
+[[- range $val := .]]
+| [[$val.zh]] |
+[[- end]]
\ No newline at end of file
@@ -0,0 +1 @@
+{{range .Items}}{{.name}} {{end}}| {{.Page}}/{{.TotalPages}} | {{.PrevURL}} | {{.NextURL}}
\ No newline at end of file
@@ -0,0 +1,3 @@
+# {{.zh}}
+
+{{.en}}
\ No newline at end of file
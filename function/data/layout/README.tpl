@@ -0,0 +1,7 @@
+#!yaml-readme --layout base.tpl -p function/data/*.yaml --output README.md
+{{define "title"}}Items{{end}}
+| Zh | En |
+|---|---|
+{{- range $val := .}}
+| {{$val.zh}} | {{$val.en}} |
+{{- end}}
\ No newline at end of file
@@ -0,0 +1,5 @@
+# {{block "title" .}}Default title{{end}}
+
+{{block "content" .}}No content{{end}}
+
+{{block "footer" .}}> Total: {{len .}}{{end}}
\ No newline at end of file
@@ -0,0 +1,8 @@
+#!yaml-readme -p function/data/*.yaml --output README.md
+| Zh | En |
+|---|---|
+{{- range $val := .}}
+{{include "partials/row.tpl" $val}}
+{{- end}}
+
+{{template "footer" .}}
\ No newline at end of file
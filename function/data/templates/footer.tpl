@@ -0,0 +1 @@
+{{define "footer"}}> Total: {{len .}}{{end}}
\ No newline at end of file
@@ -0,0 +1 @@
+| {{.zh}} | {{.en}} |
\ No newline at end of file
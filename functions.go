@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Masterminds/sprig"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// funcMeta is the metadata of a template function
+type funcMeta struct {
+	Name        string `json:"name"`
+	Signature   string `json:"signature"`
+	Description string `json:"description"`
+	Example     string `json:"example,omitempty"`
+	// Network indicates if the function sends requests, it might be slow or limited by the rate of the API
+	Network bool   `json:"network"`
+	Source  string `json:"source"`
+}
+
+// builtInFuncs is the metadata of the built-in functions, the signatures come from the functions
+var builtInFuncs = map[string]funcMeta{
+	"include": {
+		Description: "Render a shared template as a string, see also `--template-dir`",
+		Example:     `{{include "partials/row.tpl" .}}`,
+	},
+	"printHelp": {
+		Description: "Print the help text of a command or its subcommand",
+		Example:     `{{printHelp "hd" "install"}}`,
+	},
+	"printCommands": {
+		Description: "Print the reference of a command and all its subcommands",
+		Example:     `{{printCommands "hd"}}`,
+	},
+	"printToc": {
+		Description: "Print the TOC of the generated file",
+		Example:     `{{printToc}}`,
+	},
+	"printContributors": {
+		Description: "Print all the contributors of a repository",
+		Example:     `{{printContributors "linuxsuren" "yaml-readme"}}`,
+		Network:     true,
+	},
+	"printStarHistory": {
+		Description: "Print the star history chart of a repository",
+		Example:     `{{printStarHistory "linuxsuren" "yaml-readme"}}`,
+	},
+	"printVisitorCount": {
+		Description: "Print the visitor count chart of a repository",
+		Example:     `{{printVisitorCount "repo-id"}}`,
+	},
+	"printPages": {
+		Description: "Print all the repositories that pages enabled",
+		Example:     `{{printPages "linuxsuren"}}`,
+		Network:     true,
+	},
+	"printGHTable": {
+		Description: "Print the profile of a GitHub user as a table",
+		Example:     `{{printGHTable "linuxsuren"}}`,
+		Network:     true,
+	},
+	"snippet": {
+		Description: "Print a region or a line range of a file as a code block, the path is relative to the template file",
+		Example:     `{{snippet "main.go" "example"}}`,
+	},
+	"readFile": {
+		Description: "Read a file, the path is relative to the template file and in the repository",
+		Example:     `{{readFile "docs/usage.txt"}}`,
+	},
+	"includeMarkdown": {
+		Description: "Include a Markdown file without the front matter, and shift the levels of its headings",
+		Example:     `{{includeMarkdown "docs/install.md" 1}}`,
+	},
+	"renderFile": {
+		Description: "Render a template file with the data, the path is relative to the template file and in the repository",
+		Example:     `{{renderFile "docs/item.tpl" .}}`,
+	},
+	"render": {
+		Description: "Make the value be readable, turn `true` to `:white_check_mark:`",
+		Example:     `{{render true}}`,
+	},
+	"exec": {
+		Description: "Run a command in the directory of the template file and print the stdout, it works with `--allow-exec`",
+		Example:     `{{exec "go" "version"}}`,
+	},
+	"execWith": {
+		Description: "Run a command with the options: dir, env, timeout, it works with `--allow-exec`",
+		Example:     `{{execWith (dict "dir" "docs" "timeout" "1m") "make" "help"}}`,
+	},
+	"goModules": {
+		Description: "Parse a go.mod file into the Go version, the direct and indirect requirements, and the replacements",
+		Example:     `{{range (goModules "go.mod").Direct}}{{link .Path .URL}}{{end}}`,
+	},
+	"gh": {
+		Description: "Render a GitHub user to be a link, with the bio if the second argument is true",
+		Example:     `{{gh "linuxsuren" true}}`,
+		Network:     true,
+	},
+	"ghs": {
+		Description: "Render multiple GitHub users to be links",
+		Example:     `{{ghs "linuxsuren, linuxsuren" ","}}`,
+		Network:     true,
+	},
+	"ghEmoji": {
+		Description: "Print a Markdown style link of a GitHub user with Emoji",
+		Example:     `{{ghEmoji "linuxsuren"}}`,
+	},
+	"ghID": {
+		Description: "Get the GitHub user ID from a Markdown style link",
+		Example:     `{{ghID "[Rick](https://github.com/linuxsuren)"}}`,
+	},
+	"link": {
+		Description: "Print a Markdown style link",
+		Example:     `{{link "text" "link"}}`,
+	},
+	"linkOrEmpty": {
+		Description: "Print a Markdown style link or empty if text is none",
+		Example:     `{{linkOrEmpty "text" "link"}}`,
+	},
+	"twitterLink": {
+		Description: "Print a Twitter icon which links to a user",
+		Example:     `{{twitterLink "linuxsuren"}}`,
+	},
+	"youTubeLink": {
+		Description: "Print a YouTube icon which links to a channel",
+		Example:     `{{youTubeLink "channel/id"}}`,
+	},
+	"gstatic": {
+		Description: "Get the icon URL of a known site, it could be twitter, youtube",
+		Example:     `{{gstatic "twitter"}}`,
+	},
+}
+
+// getFuncMetas returns the metadata of the built-in and Sprig functions, the built-in ones come first
+func getFuncMetas() (metas []funcMeta) {
+	all := map[string]funcMeta{}
+	for name, fn := range getFuncMap("") {
+		meta := builtInFuncs[name]
+		meta.Name, meta.Signature, meta.Source = name, funcSignature(name, fn), "built-in"
+		all[name] = meta
+	}
+	// the Sprig functions override the built-in ones which have the same names, as the same as rendering
+	for name, fn := range sprig.TxtFuncMap() {
+		all[name] = funcMeta{
+			Name:        name,
+			Signature:   funcSignature(name, fn),
+			Description: fmt.Sprintf("See also http://masterminds.github.io/sprig/ for the usage of %s", name),
+			Source:      "sprig",
+		}
+	}
+	for _, meta := range all {
+		metas = append(metas, meta)
+	}
+
+	sort.SliceStable(metas, func(i, j int) bool {
+		if metas[i].Source != metas[j].Source {
+			return metas[i].Source == "built-in"
+		}
+		return metas[i].Name < metas[j].Name
+	})
+	return
+}
+
+// funcSignature returns the signature of a function, for instance: link(string, string) string
+func funcSignature(name string, fn interface{}) string {
+	return strings.Replace(reflect.TypeOf(fn).String(), "func", name, 1)
+}
+
+// printFunctions prints the metadata of all the functions as a Markdown table, JSON, or names which is the default
+func printFunctions(stdout io.Writer, format string) (err error) {
+	metas := getFuncMetas()
+	switch format {
+	case "table":
+		_, _ = fmt.Fprintln(stdout, "| Name | Signature | Description | Example | Network | Source |")
+		_, _ = fmt.Fprint(stdout, "|---|---|---|---|---|---|")
+		for _, meta := range metas {
+			var network string
+			if meta.Network {
+				network = "yes"
+			}
+			_, _ = fmt.Fprintf(stdout, "\n| `%s` | `%s` | %s | %s | %s | %s |", meta.Name, escapeTableCell(meta.Signature),
+				escapeTableCell(meta.Description), codeOrEmpty("", meta.Example), network, meta.Source)
+		}
+	case "json":
+		var data []byte
+		if data, err = json.MarshalIndent(metas, "", "  "); err == nil {
+			_, err = stdout.Write(data)
+		}
+	case "", "name":
+		names := make([]string, len(metas))
+		for i, meta := range metas {
+			names[i] = meta.Name
+		}
+		_, err = stdout.Write([]byte(strings.Join(names, "\n")))
+	default:
+		err = fmt.Errorf("unsupported format %q, it should be one of name, table, json", format)
+	}
+	return
+}
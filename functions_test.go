@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func Test_getFuncMetas(t *testing.T) {
+	metas := getFuncMetas()
+	names := map[string]funcMeta{}
+	for _, meta := range metas {
+		names[meta.Name] = meta
+	}
+
+	// all the built-in functions should be documented
+	for name := range getFuncMap("") {
+		meta := names[name]
+		assert.Equal(t, "built-in", meta.Source, name)
+		assert.NotEmpty(t, meta.Description, name)
+		assert.NotEmpty(t, meta.Example, name)
+	}
+	for name := range builtInFuncs {
+		assert.Contains(t, getFuncMap(""), name)
+	}
+
+	assert.Equal(t, funcMeta{
+		Name:        "link",
+		Signature:   "link(string, string) string",
+		Description: "Print a Markdown style link",
+		Example:     `{{link "text" "link"}}`,
+		Source:      "built-in",
+	}, names["link"])
+	assert.True(t, names["printContributors"].Network)
+	assert.Equal(t, "sprig", names["upper"].Source)
+	assert.Equal(t, "upper(string) string", names["upper"].Signature)
+
+	assert.Equal(t, "built-in", metas[0].Source)
+	assert.Equal(t, "sprig", metas[len(metas)-1].Source)
+}
+
+func Test_printFunctions(t *testing.T) {
+	buf := bytes.NewBuffer([]byte{})
+	assert.Nil(t, printFunctions(buf, "table"))
+	assert.True(t, strings.HasPrefix(buf.String(), "| Name | Signature | Description | Example | Network | Source |\n|---|---|---|---|---|---|\n"))
+	assert.Contains(t, buf.String(), "\n| `gh` | `gh(string, bool) string` | Render a GitHub user to be a link, with the bio if the second argument is true | "+
+		"`{{gh \"linuxsuren\" true}}` | yes | built-in |\n")
+
+	buf.Reset()
+	assert.Nil(t, printFunctions(buf, "json"))
+	var metas []funcMeta
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &metas))
+	assert.Equal(t, getFuncMetas(), metas)
+
+	buf.Reset()
+	assert.Nil(t, printFunctions(buf, "name"))
+	assert.Equal(t, len(getFuncMetas()), len(strings.Split(buf.String(), "\n")))
+
+	assert.NotNil(t, printFunctions(buf, "fake"))
+
+	// the names are printed by default
+	buf.Reset()
+	cmd := newRootCommand()
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"--print-functions"})
+	assert.Nil(t, cmd.Execute())
+	names := strings.Split(buf.String(), "\n")
+	assert.Equal(t, len(getFuncMetas()), len(names))
+	assert.Contains(t, names, "printHelp")
+}
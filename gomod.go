@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// goModule is the parsed go.mod file
+type goModule struct {
+	Module    string
+	GoVersion string
+	// Require has all the requirements, Direct and Indirect are parts of it
+	Require  []goRequirement
+	Direct   []goRequirement
+	Indirect []goRequirement
+	Replace  []goReplace
+}
+
+// goRequirement is a required module, the URL is the link of pkg.go.dev
+type goRequirement struct {
+	Path     string
+	Version  string
+	Indirect bool
+	URL      string
+	// Replace is the replacement of the module, it is nil if there is none
+	Replace *goReplace
+}
+
+// goReplace is a replace directive, the new one is a local path if Local is true
+type goReplace struct {
+	Old        string
+	OldVersion string
+	New        string
+	NewVersion string
+	Local      bool
+}
+
+// goModules parses a go.mod file in the scope without any network access
+func (s fileScope) goModules(path string) (mod *goModule, err error) {
+	var content string
+	if content, err = s.readFile(path); err != nil {
+		return
+	}
+	if mod, err = parseGoMod(content); err != nil {
+		err = fmt.Errorf("failed to parse %q, error: %v", path, err)
+	}
+	return
+}
+
+// parseGoMod parses the directives of a go.mod file, the unknown directives are ignored
+func parseGoMod(content string) (mod *goModule, err error) {
+	mod = &goModule{}
+	var block string
+	for i, line := range strings.Split(content, "\n") {
+		line, comment := splitGoModComment(line)
+		if block != "" {
+			if line == ")" {
+				block = ""
+			} else if line != "" {
+				err = mod.addDirective(block, strings.Fields(line), comment)
+			}
+		} else if strings.HasSuffix(line, "(") {
+			block = strings.TrimSpace(strings.TrimSuffix(line, "("))
+		} else if fields := strings.Fields(line); len(fields) > 0 {
+			err = mod.addDirective(fields[0], fields[1:], comment)
+		}
+
+		if err != nil {
+			err = fmt.Errorf("line %d: %v", i+1, err)
+			return
+		}
+	}
+
+	for i := range mod.Require {
+		require := &mod.Require[i]
+		// a version-specific replacement has a higher priority than the one for all versions
+		for j := range mod.Replace {
+			replace := mod.Replace[j]
+			if replace.Old != require.Path || (replace.OldVersion != "" && replace.OldVersion != require.Version) {
+				continue
+			}
+			if require.Replace == nil || require.Replace.OldVersion == "" {
+				require.Replace = &replace
+			}
+		}
+
+		require.URL = fmt.Sprintf("https://pkg.go.dev/%s@%s", require.Path, require.Version)
+		if require.Replace != nil && !require.Replace.Local {
+			require.URL = fmt.Sprintf("https://pkg.go.dev/%s@%s", require.Replace.New, require.Replace.NewVersion)
+		}
+
+		if require.Indirect {
+			mod.Indirect = append(mod.Indirect, *require)
+		} else {
+			mod.Direct = append(mod.Direct, *require)
+		}
+	}
+	return
+}
+
+func (m *goModule) addDirective(verb string, args []string, comment string) (err error) {
+	switch verb {
+	case "module":
+		if len(args) != 1 {
+			return fmt.Errorf("invalid module directive")
+		}
+		m.Module = unquoteGoModPath(args[0])
+	case "go":
+		if len(args) != 1 {
+			return fmt.Errorf("invalid go directive")
+		}
+		m.GoVersion = args[0]
+	case "require":
+		if len(args) != 2 {
+			return fmt.Errorf("invalid require directive, it should be: require path version")
+		}
+		m.Require = append(m.Require, goRequirement{
+			Path:     unquoteGoModPath(args[0]),
+			Version:  args[1],
+			Indirect: comment == "indirect" || strings.HasPrefix(comment, "indirect;"),
+		})
+	case "replace":
+		arrow := -1
+		for i, arg := range args {
+			if arg == "=>" {
+				arrow = i
+			}
+		}
+		if arrow < 1 || arrow > 2 || len(args)-arrow-1 < 1 || len(args)-arrow-1 > 2 {
+			return fmt.Errorf("invalid replace directive, it should be: replace path [version] => path [version]")
+		}
+
+		replace := goReplace{Old: unquoteGoModPath(args[0]), New: unquoteGoModPath(args[arrow+1])}
+		if arrow == 2 {
+			replace.OldVersion = args[1]
+		}
+		if len(args) == arrow+3 {
+			replace.NewVersion = args[arrow+2]
+		}
+		replace.Local = strings.HasPrefix(replace.New, "./") || strings.HasPrefix(replace.New, "../") ||
+			filepath.IsAbs(replace.New)
+		m.Replace = append(m.Replace, replace)
+	}
+	return
+}
+
+// splitGoModComment returns the trimmed line without the comment, and the trimmed comment
+func splitGoModComment(line string) (content, comment string) {
+	content = line
+	if index := strings.Index(line, "//"); index >= 0 {
+		content, comment = line[:index], strings.TrimSpace(line[index+2:])
+	}
+	content = strings.TrimSpace(content)
+	return
+}
+
+func unquoteGoModPath(path string) string {
+	if unquoted, err := strconv.Unquote(path); err == nil {
+		return unquoted
+	}
+	return path
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_parseGoMod(t *testing.T) {
+	mod, err := parseGoMod(`// the comment of the module
+module "github.com/linuxsuren/yaml-readme"
+
+go 1.17
+
+require github.com/spf13/cobra v1.4.0
+
+require (
+	github.com/linuxsuren/http-downloader v0.0.6
+	gopkg.in/yaml.v2 v2.4.0 // indirect
+	github.com/google/uuid v1.3.0 // indirect; for the tests
+)
+
+exclude github.com/spf13/cobra v1.3.0
+
+replace github.com/linuxsuren/http-downloader => ../http-downloader
+
+replace (
+	gopkg.in/yaml.v2 v2.4.0 => github.com/go-yaml/yaml v2.1.0+incompatible
+	github.com/google/uuid v1.2.0 => github.com/google/uuid v1.3.1
+)
+`)
+	assert.Nil(t, err)
+
+	localReplace := &goReplace{Old: "github.com/linuxsuren/http-downloader", New: "../http-downloader", Local: true}
+	yamlReplace := &goReplace{Old: "gopkg.in/yaml.v2", OldVersion: "v2.4.0", New: "github.com/go-yaml/yaml", NewVersion: "v2.1.0+incompatible"}
+	cobra := goRequirement{Path: "github.com/spf13/cobra", Version: "v1.4.0", URL: "https://pkg.go.dev/github.com/spf13/cobra@v1.4.0"}
+	downloader := goRequirement{Path: "github.com/linuxsuren/http-downloader", Version: "v0.0.6",
+		URL: "https://pkg.go.dev/github.com/linuxsuren/http-downloader@v0.0.6", Replace: localReplace}
+	yaml := goRequirement{Path: "gopkg.in/yaml.v2", Version: "v2.4.0", Indirect: true,
+		URL: "https://pkg.go.dev/github.com/go-yaml/yaml@v2.1.0+incompatible", Replace: yamlReplace}
+	uuid := goRequirement{Path: "github.com/google/uuid", Version: "v1.3.0", Indirect: true,
+		URL: "https://pkg.go.dev/github.com/google/uuid@v1.3.0"}
+
+	assert.Equal(t, "github.com/linuxsuren/yaml-readme", mod.Module)
+	assert.Equal(t, "1.17", mod.GoVersion)
+	assert.Equal(t, []goRequirement{cobra, downloader, yaml, uuid}, mod.Require)
+	assert.Equal(t, []goRequirement{cobra, downloader}, mod.Direct)
+	assert.Equal(t, []goRequirement{yaml, uuid}, mod.Indirect)
+	assert.Equal(t, []goReplace{*localReplace, *yamlReplace, {
+		Old: "github.com/google/uuid", OldVersion: "v1.2.0", New: "github.com/google/uuid", NewVersion: "v1.3.1",
+	}}, mod.Replace)
+}
+
+func Test_parseGoModReplacePriority(t *testing.T) {
+	for _, content := range []string{`require github.com/spf13/cobra v1.4.0
+replace github.com/spf13/cobra v1.4.0 => github.com/linuxsuren/cobra v1.4.1
+replace github.com/spf13/cobra => ../cobra`, `require github.com/spf13/cobra v1.4.0
+replace github.com/spf13/cobra => ../cobra
+replace github.com/spf13/cobra v1.4.0 => github.com/linuxsuren/cobra v1.4.1`} {
+		mod, err := parseGoMod(content)
+		assert.Nil(t, err)
+		// the version-specific one wins no matter the order
+		assert.Equal(t, &goReplace{Old: "github.com/spf13/cobra", OldVersion: "v1.4.0", New: "github.com/linuxsuren/cobra", NewVersion: "v1.4.1"},
+			mod.Require[0].Replace, content)
+		assert.Equal(t, "https://pkg.go.dev/github.com/linuxsuren/cobra@v1.4.1", mod.Require[0].URL)
+	}
+}
+
+func Test_parseGoModWithErrors(t *testing.T) {
+	for _, content := range []string{
+		"module",
+		"go 1.17 1.18",
+		"require github.com/spf13/cobra",
+		"require (\n\tgithub.com/spf13/cobra\n)",
+		"replace github.com/spf13/cobra v1.4.0",
+		"replace github.com/spf13/cobra => ",
+	} {
+		_, err := parseGoMod(content)
+		assert.NotNil(t, err, content)
+	}
+}
+
+func Test_goModules(t *testing.T) {
+	// render the requirements of this repository
+	output, err := (&option{templateFile: "README.tpl"}).renderTemplateToString(`{{with goModules "go.mod"}}Go {{.GoVersion}}
+{{range .Direct}}{{if eq .Path "github.com/spf13/cobra"}}{{link .Path .URL}}{{end}}{{end}}{{end}}`, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "Go 1.17\n[github.com/spf13/cobra](https://pkg.go.dev/github.com/spf13/cobra@v1.4.0)", output)
+
+	_, err = newFileScope("README.tpl").goModules("fake.mod")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"github.com/Masterminds/sprig"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+const (
+	quoteHeader = `> This file was generated by [{{.Template}}]({{.Template}}) via [yaml-readme](https://github.com/LinuxSuRen/yaml-readme), please don't edit it directly!`
+
+	commentHeader = `This file was generated by {{.Template}} with the data from {{.Pattern}} via https://github.com/LinuxSuRen/yaml-readme, please don't edit it directly! Content hash: {{.Hash}}`
+)
+
+// generatedMarker is a part of the default headers, it tells the files which were generated by yaml-readme
+const generatedMarker = "https://github.com/LinuxSuRen/yaml-readme"
+
+// headerContext is the context of the header template
+type headerContext struct {
+	// Template is the file name of the template
+	Template string
+	// Pattern is the pattern of the data files
+	Pattern string
+	// Hash is the SHA-256 of the content without the header
+	Hash string
+}
+
+// addHeader renders the header, and puts it on the top of the content or after the front matter.
+// The header is a comment which fits the type of the output file in case there is a front matter.
+func (o *option) addHeader(templateFile, output string, content []byte) (result []byte, err error) {
+	result = content
+	if !o.includeHeader {
+		return
+	}
+
+	frontMatter, body := splitFrontMatter(string(content))
+
+	style := o.header
+	if style == "" {
+		style = "quote"
+	}
+	// the quote is visible in the page of static site generators
+	if style == "quote" && frontMatter != "" {
+		style = "comment"
+	}
+
+	headerTpl := o.header
+	switch style {
+	case "quote":
+		headerTpl = quoteHeader
+	case "comment":
+		headerTpl = commentHeader
+	}
+
+	var tpl *template.Template
+	if tpl, err = template.New("header").Funcs(sprig.TxtFuncMap()).Parse(headerTpl); err != nil {
+		err = fmt.Errorf("failed to parse the header template, error: %v", err)
+		return
+	}
+
+	buf := bytes.NewBuffer([]byte{})
+	if err = tpl.Execute(buf, headerContext{
+		Template: filepath.Base(templateFile),
+		Pattern:  o.pattern,
+		Hash:     fmt.Sprintf("%x", sha256.Sum256(content)),
+	}); err == nil {
+		header := strings.TrimRight(buf.String(), "\n")
+		if style == "comment" {
+			header = commentOut(header, output)
+		}
+		result = []byte(frontMatter + header + "\n\n" + body)
+	}
+	return
+}
+
+// isGeneratedFile checks if the header of a file, which is the first line after the front matter,
+// is one of the default headers of the template file
+func isGeneratedFile(file, templateFile string) (generated bool, err error) {
+	var data []byte
+	if data, err = ioutil.ReadFile(file); err != nil {
+		return
+	}
+	_, body := splitFrontMatter(string(data))
+	header := strings.SplitN(strings.TrimLeft(body, "\r\n"), "\n", 2)[0]
+	name := filepath.Base(templateFile)
+	generated = strings.Contains(header, generatedMarker) && (strings.Contains(header, "generated by ["+name+"]("+name+")") ||
+		strings.Contains(header, "generated by "+name+" with the data from"))
+	return
+}
+
+// hasDefaultHeader checks if the generated files have a default header, which tells the files could be pruned
+func (o *option) hasDefaultHeader() bool {
+	return o.includeHeader && (o.header == "" || o.header == "quote" || o.header == "comment")
+}
+
+// splitFrontMatter splits the leading YAML (---) or TOML (+++) front matter from the content
+func splitFrontMatter(content string) (frontMatter, body string) {
+	body = content
+	for _, delimiter := range []string{"---", "+++"} {
+		if !strings.HasPrefix(content, delimiter+"\n") && !strings.HasPrefix(content, delimiter+"\r\n") {
+			continue
+		}
+
+		lines := strings.SplitAfter(content, "\n")
+		offset := len(lines[0])
+		for _, line := range lines[1:] {
+			offset += len(line)
+			if strings.TrimRight(line, "\r\n") == delimiter {
+				frontMatter, body = content[:offset], content[offset:]
+				if !strings.HasSuffix(frontMatter, "\n") {
+					frontMatter += "\n"
+				}
+				return
+			}
+		}
+	}
+	return
+}
+
+// commentOut turns the text into a comment which fits the type of the output file, it is HTML comment by default
+func commentOut(text, output string) string {
+	var prefix string
+	switch strings.ToLower(filepath.Ext(output)) {
+	case ".adoc", ".asciidoc":
+		prefix = "// "
+	case ".yaml", ".yml", ".toml", ".sh":
+		prefix = "# "
+	case ".rst":
+		return ".. " + strings.ReplaceAll(text, "\n", "\n   ")
+	default:
+		return "<!-- " + text + " -->"
+	}
+
+	lines := strings.Split(text, "\n")
+	for i := range lines {
+		lines[i] = prefix + lines[i]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_addHeader(t *testing.T) {
+	tests := []struct {
+		name       string
+		opt        *option
+		output     string
+		content    string
+		wantOutput string
+		wantErr    bool
+	}{{
+		name:       "without header",
+		opt:        &option{},
+		content:    "content",
+		wantOutput: "content",
+	}, {
+		name:    "default header",
+		opt:     &option{includeHeader: true},
+		content: "content",
+		wantOutput: `> This file was generated by [README.tpl](README.tpl) via [yaml-readme](https://github.com/LinuxSuRen/yaml-readme), please don't edit it directly!
+
+content`,
+	}, {
+		name:    "HTML comment header",
+		opt:     &option{includeHeader: true, header: "comment", pattern: "items/*.yaml"},
+		content: "content",
+		wantOutput: `<!-- This file was generated by README.tpl with the data from items/*.yaml via https://github.com/LinuxSuRen/yaml-readme, please don't edit it directly! Content hash: ed7002b439e9ac845f22357d822bac1444730fbdb6016d3ec9432297b9ec9f73 -->
+
+content`,
+	}, {
+		name:    "custom header",
+		opt:     &option{includeHeader: true, header: "<!-- {{.Template}} {{.Pattern}} {{.Hash | trunc 7}} -->\n", pattern: "items/*.yaml"},
+		content: "content",
+		wantOutput: `<!-- README.tpl items/*.yaml ed7002b -->
+
+content`,
+	}, {
+		name:   "after the YAML front matter",
+		opt:    &option{includeHeader: true, pattern: "items/*.yaml"},
+		output: "content/posts/tools.md",
+		content: `---
+title: Tools
+---
+content`,
+		wantOutput: `---
+title: Tools
+---
+<!-- This file was generated by README.tpl with the data from items/*.yaml via https://github.com/LinuxSuRen/yaml-readme, please don't edit it directly! Content hash: 4d319aa11883cb43c52a54483a365890cfef9bc2a158bd0d702c83c167475a95 -->
+
+content`,
+	}, {
+		name:   "after the TOML front matter of an AsciiDoc file",
+		opt:    &option{includeHeader: true, header: "generated by {{.Template}}\nplease don't edit it"},
+		output: "tools.adoc",
+		content: `+++
+title = "Tools"
++++
+content`,
+		wantOutput: `+++
+title = "Tools"
++++
+generated by README.tpl
+please don't edit it
+
+content`,
+	}, {
+		name:   "comment in reStructuredText",
+		opt:    &option{includeHeader: true, header: "comment"},
+		output: "README.rst",
+		content: `---
+not a front matter`,
+		wantOutput: `.. This file was generated by README.tpl with the data from  via https://github.com/LinuxSuRen/yaml-readme, please don't edit it directly! Content hash: 346acddd848adc999daf3d07d5c8e5582806b70907d92509746e4208e1bb9080
+
+---
+not a front matter`,
+	}, {
+		name:    "invalid header template",
+		opt:     &option{includeHeader: true, header: "{{.Template"},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := tt.opt.addHeader("function/data/README.tpl", tt.output, []byte(tt.content))
+			assert.Equal(t, tt.wantErr, err != nil)
+			if !tt.wantErr {
+				assert.Equal(t, tt.wantOutput, string(output))
+			}
+		})
+	}
+}
+
+func Test_commentOut(t *testing.T) {
+	assert.Equal(t, "<!-- text -->", commentOut("text", ""))
+	assert.Equal(t, "<!-- text -->", commentOut("text", "index.html"))
+	assert.Equal(t, "// line 1\n// line 2", commentOut("line 1\nline 2", "README.adoc"))
+	assert.Equal(t, "# text", commentOut("text", "values.yaml"))
+	assert.Equal(t, ".. line 1\n   line 2", commentOut("line 1\nline 2", "README.rst"))
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// helpOption is the option of printHelp
+type helpOption struct {
+	timeout time.Duration
+	// allowlist is the commands which are allowed to run, all commands are allowed if it is empty
+	allowlist []string
+	// dir is the working directory of the commands
+	dir string
+	// strict indicates if it fails the rendering when a command cannot run
+	strict bool
+}
+
+// defaultHelpOption allows all the commands, and ignores the commands which cannot run
+var defaultHelpOption = helpOption{timeout: 10 * time.Second}
+
+// ansiPattern matches the ANSI escape sequences, such as colors
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]`)
+
+// getHelpOption returns the option of printHelp, the commands run in the directory of the template file
+func (o *option) getHelpOption() (help helpOption, err error) {
+	help = helpOption{
+		timeout:   o.helpTimeout,
+		allowlist: o.helpAllowlist,
+		dir:       filepath.Dir(o.templateFile),
+		strict:    o.strict,
+	}
+	if help.timeout == 0 {
+		help.timeout = defaultHelpOption.timeout
+	} else if help.timeout < 0 {
+		err = fmt.Errorf("invalid help timeout %v, it should be positive", help.timeout)
+	}
+	return
+}
+
+// printHelp runs the command with the args and --help, and returns the help text as a code block
+func printHelp(option helpOption, cmd string, args ...string) (output string, err error) {
+	if err = option.checkCommand(cmd, args); err != nil {
+		return
+	}
+
+	var data []byte
+	if data, err = runHelp(option, cmd, args...); err != nil {
+		if option.strict {
+			return
+		}
+		logger.Printf("failed to run command [%s], error: %v\n", cmd, err)
+		err = nil
+		return
+	}
+
+	output = fmt.Sprintf(`%s
+%s
+%s`, "```shell", cleanHelp(string(data)), "```")
+	return
+}
+
+// checkCommand makes sure that a template cannot run any command via printHelp. The command should be in the allowlist,
+// and the subcommands should look like the names of subcommands instead of flags or files.
+func (h helpOption) checkCommand(cmd string, args []string) error {
+	if len(h.allowlist) > 0 && !contains(h.allowlist, cmd) {
+		return fmt.Errorf("command [%s] is not in the allowlist %v", cmd, h.allowlist)
+	}
+	for _, arg := range args {
+		if !isSubcommand(arg) {
+			return fmt.Errorf("invalid subcommand %q of [%s], it should be a name like install or add-repo", arg, cmd)
+		}
+	}
+	return nil
+}
+
+var subcommandPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_:-]*$`)
+
+func isSubcommand(arg string) bool {
+	return subcommandPattern.MatchString(arg)
+}
+
+func runHelp(option helpOption, cmd string, args ...string) ([]byte, error) {
+	command := execCommand{name: cmd, args: append(args, "--help"), dir: option.dir, timeout: option.timeout}
+	return command.run()
+}
+
+// cleanHelp removes the ANSI escape sequences, the trailing whitespaces of the lines, and the trailing empty lines
+func cleanHelp(text string) string {
+	lines := strings.Split(ansiPattern.ReplaceAllString(text, ""), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " \t\r")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_printHelp(t *testing.T) {
+	dir := t.TempDir()
+	fakeCLI := filepath.Join(dir, "fake-cli")
+	err := ioutil.WriteFile(fakeCLI, []byte("#!/bin/sh\nprintf '\\033[1;32mUsage:\\033[0m %s  \\n\\n' \"$*\"\n"), 0755)
+	assert.Nil(t, err)
+	slowCLI := filepath.Join(dir, "slow-cli")
+	err = ioutil.WriteFile(slowCLI, []byte("#!/bin/sh\nexec sleep 5\n"), 0755)
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name     string
+		option   helpOption
+		cmd      string
+		args     []string
+		expect   string
+		hasError bool
+	}{{
+		name:   "subcommand with colors",
+		option: helpOption{timeout: time.Second, allowlist: []string{fakeCLI}},
+		cmd:    fakeCLI,
+		args:   []string{"install"},
+		expect: "```shell\nUsage: install --help\n```",
+	}, {
+		name:   "missing command",
+		option: defaultHelpOption,
+		cmd:    filepath.Join(dir, "fake"),
+		expect: "",
+	}, {
+		name:     "missing command in strict mode",
+		option:   helpOption{timeout: time.Second, strict: true},
+		cmd:      filepath.Join(dir, "fake"),
+		hasError: true,
+	}, {
+		name:     "timeout in strict mode",
+		option:   helpOption{timeout: 100 * time.Millisecond, strict: true},
+		cmd:      slowCLI,
+		hasError: true,
+	}, {
+		name:     "not in the allowlist",
+		option:   helpOption{timeout: time.Second, allowlist: []string{"hd"}},
+		cmd:      fakeCLI,
+		hasError: true,
+	}, {
+		name:   "in the allowlist",
+		option: helpOption{timeout: time.Second, allowlist: []string{fakeCLI}},
+		cmd:    fakeCLI,
+		expect: "```shell\nUsage: --help\n```",
+	}, {
+		name:   "subcommand without an allowlist",
+		option: defaultHelpOption,
+		cmd:    fakeCLI,
+		args:   []string{"install", "add-repo"},
+		expect: "```shell\nUsage: install add-repo --help\n```",
+	}, {
+		name:     "flag as a subcommand",
+		option:   helpOption{timeout: time.Second, allowlist: []string{"sh"}},
+		cmd:      "sh",
+		args:     []string{"-c", "echo hello"},
+		hasError: true,
+	}, {
+		name:     "file as a subcommand",
+		option:   defaultHelpOption,
+		cmd:      "sh",
+		args:     []string{"script.sh"},
+		hasError: true,
+	}, {
+		name:     "empty subcommand",
+		option:   defaultHelpOption,
+		cmd:      fakeCLI,
+		args:     []string{" "},
+		hasError: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := printHelp(tt.option, tt.cmd, tt.args...)
+			if tt.hasError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expect, output)
+			}
+		})
+	}
+}
+
+func Test_getHelpOption(t *testing.T) {
+	help, err := (&option{templateFile: "docs/README.tpl"}).getHelpOption()
+	assert.Nil(t, err)
+	assert.Equal(t, helpOption{timeout: defaultHelpOption.timeout, dir: "docs"}, help)
+
+	_, err = (&option{helpTimeout: -time.Second}).getHelpOption()
+	assert.NotNil(t, err)
+}
+
+func Test_helpAllowlistInDirective(t *testing.T) {
+	dir := t.TempDir()
+	templateFile := filepath.Join(dir, "README.tpl")
+	err := ioutil.WriteFile(templateFile, []byte("#!yaml-readme --help-allowlist sh\n{{printHelp \"sh\"}}"), 0644)
+	assert.Nil(t, err)
+
+	// a template cannot change the allowlist by itself
+	cmd := newRootCommand()
+	cmd.SetOut(bytes.NewBuffer([]byte{}))
+	cmd.SetArgs([]string{"--template", templateFile, "--pattern", "function/data/*.yaml", "--help-allowlist", "hd"})
+	assert.NotNil(t, cmd.Execute())
+}
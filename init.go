@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"log"
+	"os"
+	"strings"
+)
+
+type initOption struct {
+	option
+	force bool
+}
+
+func newInitCommand() (cmd *cobra.Command) {
+	opt := &initOption{}
+	cmd = &cobra.Command{
+		Use:   "init",
+		Short: "Generate an editable template file from a preset",
+		Long: `Generate an editable template file from a preset
+The columns of the template come from the fields of the items`,
+		RunE: opt.runE,
+	}
+	flags := cmd.Flags()
+	flags.StringVarP(&opt.pattern, "pattern", "p", "items/*.yaml",
+		"The glob pattern with Golang spec to find files")
+	flags.StringVarP(&opt.templateFile, "template", "t", "README.tpl",
+		"The template file to write")
+	flags.StringVarP(&opt.preset, "preset", "", "",
+		"The preset of the template, it could be table, list, cards, grouped-table. "+
+			"It is grouped-table if --group-by is set, or table by default")
+	flags.StringVarP(&opt.groupBy, "group-by", "", "",
+		"Group the array data by which field")
+	flags.StringVarP(&opt.delims, "delims", "", "",
+		"The left and right delimiters of the template which are separated by a comma. For example: --delims '[[,]]'")
+	flags.StringVarP(&opt.cacheDir, "cache-dir", "", defaultCacheDir(),
+		"The directory to cache the data from HTTP(S) URLs")
+	flags.BoolVarP(&opt.force, "force", "f", false,
+		"Overwrite the template file if it exists")
+	return
+}
+
+func (o *initOption) runE(cmd *cobra.Command, args []string) (err error) {
+	logger = log.New(cmd.ErrOrStderr(), "", log.LstdFlags)
+	o.stdin = cmd.InOrStdin()
+
+	if _, statErr := os.Stat(o.templateFile); statErr == nil && !o.force {
+		err = fmt.Errorf("the template file %q exists, please use --force to overwrite it", o.templateFile)
+		return
+	}
+
+	var items []map[string]interface{}
+	if items, _, err = o.loadMetadata(); err != nil {
+		err = fmt.Errorf("failed to load metadata from %q, error: %v", o.pattern, err)
+		return
+	}
+
+	preset := o.preset
+	if preset == "" {
+		preset = defaultPreset(o.groupBy)
+	}
+
+	var tpl string
+	if tpl, err = o.generatePreset(preset, items); err != nil {
+		return
+	}
+
+	// keep the flags in the directive, then the template works without them
+	directive := "#!yaml-readme -p " + quoteArg(o.pattern)
+	if o.groupBy != "" {
+		directive += " --group-by " + quoteArg(o.groupBy)
+	}
+	if o.delims != "" {
+		directive += " --delims " + quoteArg(o.delims)
+	}
+	if strings.ContainsAny(directive, "\r\n") {
+		err = fmt.Errorf("the flags cannot be kept in the directive line because of the line breaks: %q", directive)
+		return
+	}
+
+	if err = writeFile(o.templateFile, []byte(directive+"\n"+tpl)); err == nil {
+		cmd.Printf("the template file %q was generated from the preset %s\n", o.templateFile, preset)
+	}
+	return
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"testing"
+)
+
+func TestInitCommand(t *testing.T) {
+	templateFile := path.Join(t.TempDir(), "README.tpl")
+
+	cmd := newRootCommand()
+	cmd.SetOut(bytes.NewBuffer([]byte{}))
+	cmd.SetArgs([]string{"init", "--pattern", "function/data/*.yaml", "--template", templateFile, "--preset", "list"})
+	assert.Nil(t, cmd.Execute())
+
+	data, err := ioutil.ReadFile(templateFile)
+	assert.Nil(t, err)
+	assert.Equal(t, `#!yaml-readme -p 'function/data/*.yaml'
+{{- range $val := .}}
+- {{if hasKey $val "en"}}{{index $val "en"}}{{end}}
+  - Jd: {{if hasKey $val "jd"}}{{index $val "jd"}}{{end}}
+  - Year: {{if hasKey $val "year"}}{{index $val "year"}}{{end}}
+  - Zh: {{if hasKey $val "zh"}}{{index $val "zh"}}{{end}}
+{{- end}}`, string(data))
+
+	// render the generated template
+	buf := bytes.NewBuffer([]byte{})
+	cmd = newRootCommand()
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"--template", templateFile, "--include-header=false"})
+	assert.Nil(t, cmd.Execute())
+	assert.Equal(t, `
+- en
+  - Jd: jd
+  - Year: 2022
+  - Zh: zh
+- en
+  - Jd: jd
+  - Year: 2021
+  - Zh: zh`, buf.String())
+
+	// do not overwrite the existing file
+	cmd = newRootCommand()
+	cmd.SetArgs([]string{"init", "--template", templateFile})
+	assert.NotNil(t, cmd.Execute())
+
+	cmd = newRootCommand()
+	cmd.SetOut(bytes.NewBuffer([]byte{}))
+	cmd.SetArgs([]string{"init", "--pattern", "function/data/*.yaml", "--template", templateFile, "--group-by", "year", "--force"})
+	assert.Nil(t, cmd.Execute())
+	data, err = ioutil.ReadFile(templateFile)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `#!yaml-readme -p 'function/data/*.yaml' --group-by 'year'`)
+	assert.Contains(t, string(data), `{{- range $key, $val := .}}`)
+
+	// the template uses the delimiters
+	cmd = newRootCommand()
+	cmd.SetOut(bytes.NewBuffer([]byte{}))
+	cmd.SetArgs([]string{"init", "--pattern", "function/data/*.yaml", "--template", templateFile, "--delims", "[[,]]", "--force"})
+	assert.Nil(t, cmd.Execute())
+	data, err = ioutil.ReadFile(templateFile)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `#!yaml-readme -p 'function/data/*.yaml' --delims '[[,]]'`)
+	assert.Contains(t, string(data), `[[- range $val := .]]`)
+
+	buf = bytes.NewBuffer([]byte{})
+	cmd = newRootCommand()
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"--template", templateFile, "--include-header=false"})
+	assert.Nil(t, cmd.Execute())
+	assert.Contains(t, buf.String(), "| en | jd | 2022 | zh |")
+}
+
+func TestInitCommandWithRemotePattern(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"name": "rick"}]`))
+	}))
+	defer server.Close()
+	// the single quote should not break the directive
+	pattern := server.URL + "/rick's.json"
+	templateFile := path.Join(t.TempDir(), "README.tpl")
+	cacheDir := t.TempDir()
+
+	cmd := newRootCommand()
+	cmd.SetOut(bytes.NewBuffer([]byte{}))
+	cmd.SetArgs([]string{"init", "--pattern", pattern, "--template", templateFile, "--cache-dir", cacheDir})
+	assert.Nil(t, cmd.Execute())
+
+	data, err := ioutil.ReadFile(templateFile)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"-p", pattern}, parseDirective(string(data)))
+
+	// the data is cached in the given directory instead of the current directory
+	files, err := ioutil.ReadDir(cacheDir)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, files)
+
+	buf := bytes.NewBuffer([]byte{})
+	cmd = newRootCommand()
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"--template", templateFile, "--include-header=false", "--cache-dir", cacheDir})
+	assert.Nil(t, cmd.Execute())
+	assert.Contains(t, buf.String(), "| rick |")
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/Masterminds/sprig"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// renderItems renders a page for each item, the output path of each page is a template as well
+func (o *option) renderItems(items []map[string]interface{}) (err error) {
+	if o.itemTemplate == "" {
+		err = fmt.Errorf("the item template is required when the item output is %q", o.itemOutput)
+		return
+	}
+	if err = o.checkPrune(); err != nil {
+		return
+	}
+
+	var itemTpl string
+	if itemTpl, err = loadTemplate(o.itemTemplate); os.IsNotExist(err) {
+		err = fmt.Errorf("%w: failed to load item template from %q, error: %v", errTemplateNotFound, o.itemTemplate, err)
+		return
+	} else if err != nil {
+		err = fmt.Errorf("failed to load item template from %q, error: %v", o.itemTemplate, err)
+		return
+	}
+
+	generated := map[string]string{}
+	for _, item := range items {
+		var output string
+		if output, err = o.renderPath(o.itemOutput, item); err != nil {
+			err = fmt.Errorf("failed to render the output path of item %q, error: %v", item["fullpath"], err)
+			return
+		}
+		output = filepath.Clean(output)
+		if err = checkOutputPath(o.itemOutputDir(), output); err != nil {
+			err = fmt.Errorf("invalid output path of item %q, error: %v", item["fullpath"], err)
+			return
+		}
+		if another, ok := generated[output]; ok {
+			err = fmt.Errorf("item %q and %q have the same output %q", another, item["fullpath"], output)
+			return
+		}
+		generated[output], _ = item["fullpath"].(string)
+
+		// the page is rendered without the layout of the index, and the engine fits its own output
+		itemOpt := *o
+		itemOpt.layoutTpl = ""
+		itemOpt.output = output
+
+		buf := bytes.NewBuffer([]byte{})
+		if err = itemOpt.renderTemplate(itemTpl, item, buf); err != nil {
+			err = fmt.Errorf("failed to render item %q, error: %w", item["fullpath"], err)
+			return
+		}
+
+		var data []byte
+		if data, err = o.addHeader(o.itemTemplate, output, buf.Bytes()); err != nil {
+			return
+		}
+		if err = writeFile(output, data); err != nil {
+			return
+		}
+	}
+
+	if o.prune {
+		var files []string
+		if files, err = filepath.Glob(o.pathToGlob(o.itemOutput)); err == nil {
+			err = o.pruneFiles(files, generated, o.itemTemplate)
+		}
+	}
+	return
+}
+
+// renderPath renders a template of a file path with only the Sprig functions
+func (o *option) renderPath(pathTpl string, object interface{}) (output string, err error) {
+	var leftDelim, rightDelim string
+	if leftDelim, rightDelim, err = o.getDelims(); err != nil {
+		return
+	}
+
+	var tpl *template.Template
+	if tpl, err = template.New("path").Delims(leftDelim, rightDelim).Option("missingkey=error").
+		Funcs(sprig.TxtFuncMap()).Parse(pathTpl); err == nil {
+		buf := bytes.NewBuffer([]byte{})
+		if err = tpl.Execute(buf, object); err == nil {
+			output = buf.String()
+		}
+	}
+	return
+}
+
+// itemOutputDir returns the static directory of the item output, for example: docs/tools of docs/tools/{{.filename}}.md
+func (o *option) itemOutputDir() string {
+	prefix := o.itemOutput
+	leftDelim, _, _ := o.getDelims()
+	if leftDelim == "" {
+		leftDelim = "{{"
+	}
+	if index := strings.Index(prefix, leftDelim); index >= 0 {
+		prefix = prefix[:index]
+	}
+	return filepath.Dir(prefix + "_")
+}
+
+// checkOutputPath makes sure the output path which comes from the item data is in the directory,
+// and the existing parent directories are not the symbolic links to the outside
+func checkOutputPath(dir, output string) (err error) {
+	var absDir, absOutput, rel string
+	if absDir, err = filepath.Abs(dir); err == nil {
+		if absOutput, err = filepath.Abs(output); err == nil {
+			rel, err = filepath.Rel(absDir, absOutput)
+		}
+	}
+	if err != nil {
+		return
+	}
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		err = fmt.Errorf("the output %q is out of the directory %q", output, dir)
+		return
+	}
+
+	if _, statErr := os.Stat(absDir); statErr != nil {
+		// nothing could point to the outside of a directory which does not exist
+		return
+	}
+	parent := filepath.Dir(absOutput)
+	for parent != absDir {
+		if _, statErr := os.Stat(parent); statErr == nil {
+			break
+		}
+		parent = filepath.Dir(parent)
+	}
+	_, err = fileScope{root: absDir, dir: absDir}.resolve(parent)
+	return
+}
+
+// pathToGlob turns a path template into a glob pattern, for example: docs/{{.filename}}.md to docs/*.md
+func (o *option) pathToGlob(pathTpl string) string {
+	leftDelim, rightDelim, _ := o.getDelims()
+	if leftDelim == "" {
+		leftDelim, rightDelim = "{{", "}}"
+	}
+	action := regexp.MustCompile(regexp.QuoteMeta(leftDelim) + ".*?" + regexp.QuoteMeta(rightDelim))
+	return action.ReplaceAllString(pathTpl, "*")
+}
+
+// checkPrune makes sure the generated files could be recognized by the header before pruning
+func (o *option) checkPrune() (err error) {
+	if o.prune && !o.hasDefaultHeader() {
+		err = fmt.Errorf("--prune works with the default headers, please do not use it with a custom --header or --include-header=false")
+	}
+	return
+}
+
+// pruneFiles removes the files which were generated by the template before but not this time.
+// The output file, the templates, and the files without the header of the template are always kept.
+func (o *option) pruneFiles(files []string, generated map[string]string, templateFile string) (err error) {
+	for _, file := range files {
+		if _, ok := generated[filepath.Clean(file)]; ok || isSameFile(file, o.output, o.templateFile, o.itemTemplate) {
+			continue
+		}
+
+		var stale bool
+		if stale, err = isGeneratedFile(file, templateFile); err != nil {
+			return
+		} else if !stale {
+			logger.Printf("keep the file [%s] which was not generated by [%s]\n", file, templateFile)
+			continue
+		}
+		logger.Printf("remove the stale file [%s]\n", file)
+		if err = os.Remove(file); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// isSameFile checks if the file is one of the others
+func isSameFile(file string, others ...string) bool {
+	info, err := os.Stat(file)
+	if err != nil {
+		return false
+	}
+	for _, other := range others {
+		if other == "" {
+			continue
+		}
+		if otherInfo, err := os.Stat(other); err == nil && os.SameFile(info, otherInfo) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeFile writes the data into a file, and creates its parent directory if necessary
+func writeFile(file string, data []byte) (err error) {
+	if err = os.MkdirAll(filepath.Dir(file), 0755); err == nil {
+		err = ioutil.WriteFile(file, data, 0644)
+	}
+	return
+}
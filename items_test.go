@@ -0,0 +1,163 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func Test_renderItems(t *testing.T) {
+	items := []map[string]interface{}{{
+		"zh": "zh", "en": "en", "filename": "item", "fullpath": "function/data/item.yaml",
+	}, {
+		"zh": "zh-2022", "en": "en-2022", "filename": "item-2022", "fullpath": "function/data/item-2022.yaml",
+	}}
+
+	t.Run("a page for each item", func(t *testing.T) {
+		dir := t.TempDir()
+		// stale files
+		assert.Nil(t, writeFile(path.Join(dir, "docs", "stale.md"), []byte("> This file was generated by [item.tpl](item.tpl) "+
+			"via [yaml-readme](https://github.com/LinuxSuRen/yaml-readme), please don't edit it directly!\n\nstale")))
+		assert.Nil(t, writeFile(path.Join(dir, "docs", "keep.txt"), []byte("keep")))
+		// the files which were not generated by yaml-readme, and the output file
+		assert.Nil(t, writeFile(path.Join(dir, "docs", "CONTRIBUTING.md"), []byte("# Contributing")))
+		assert.Nil(t, writeFile(path.Join(dir, "docs", "README.md"), []byte("> This file was generated by [README.tpl](README.tpl) "+
+			"via [yaml-readme](https://github.com/LinuxSuRen/yaml-readme), please don't edit it directly!\n\nindex")))
+
+		opt := &option{
+			output:        path.Join(dir, "docs", "README.md"),
+			itemTemplate:  "function/data/item.tpl",
+			itemOutput:    dir + "/docs/{{.filename}}.md",
+			prune:         true,
+			includeHeader: true,
+		}
+		assert.Nil(t, opt.renderItems(items))
+
+		header := "> This file was generated by [item.tpl](item.tpl) via [yaml-readme](https://github.com/LinuxSuRen/yaml-readme), " +
+			"please don't edit it directly!\n\n"
+		data, err := ioutil.ReadFile(path.Join(dir, "docs", "item.md"))
+		assert.Nil(t, err)
+		assert.Equal(t, header+"# zh\n\nen", string(data))
+		data, err = ioutil.ReadFile(path.Join(dir, "docs", "item-2022.md"))
+		assert.Nil(t, err)
+		assert.Equal(t, header+"# zh-2022\n\nen-2022", string(data))
+
+		assert.NoFileExists(t, path.Join(dir, "docs", "stale.md"))
+		assert.FileExists(t, path.Join(dir, "docs", "keep.txt"))
+		assert.FileExists(t, path.Join(dir, "docs", "CONTRIBUTING.md"))
+		assert.FileExists(t, path.Join(dir, "docs", "README.md"))
+	})
+
+	t.Run("two templates write into the same directory", func(t *testing.T) {
+		dir := t.TempDir()
+		otherTemplate := path.Join(dir, "other.tpl")
+		assert.Nil(t, writeFile(otherTemplate, []byte("{{.en}}")))
+
+		opt := &option{
+			itemTemplate:  "function/data/item.tpl",
+			itemOutput:    dir + "/docs/{{.filename}}.md",
+			prune:         true,
+			includeHeader: true,
+		}
+		assert.Nil(t, opt.renderItems(items))
+
+		other := &option{
+			itemTemplate:  otherTemplate,
+			itemOutput:    dir + "/docs/other-{{.filename}}.md",
+			prune:         true,
+			includeHeader: true,
+			header:        "comment",
+		}
+		assert.Nil(t, other.renderItems(items[:1]))
+		assert.Nil(t, opt.renderItems(items[1:]))
+
+		// each template prunes its own files only
+		assert.FileExists(t, path.Join(dir, "docs", "other-item.md"))
+		assert.NoFileExists(t, path.Join(dir, "docs", "item.md"))
+		assert.FileExists(t, path.Join(dir, "docs", "item-2022.md"))
+	})
+
+	t.Run("prune without the default header", func(t *testing.T) {
+		for _, opt := range []*option{{prune: true}, {prune: true, includeHeader: true, header: "Generated by {{.Template}}"}} {
+			opt.itemTemplate, opt.itemOutput = "function/data/item.tpl", t.TempDir()+"/{{.filename}}.md"
+			assert.NotNil(t, opt.renderItems(items))
+		}
+	})
+
+	t.Run("keep the stale files by default", func(t *testing.T) {
+		dir := t.TempDir()
+		assert.Nil(t, writeFile(path.Join(dir, "stale.md"), []byte("stale")))
+
+		opt := &option{
+			itemTemplate: "function/data/item.tpl",
+			itemOutput:   dir + "/[[.filename]].md",
+			delims:       "[[,]]",
+		}
+		assert.Nil(t, opt.renderItems(items))
+		assert.FileExists(t, path.Join(dir, "item.md"))
+		assert.FileExists(t, path.Join(dir, "stale.md"))
+	})
+
+	t.Run("without the layout, and the engine fits the item output", func(t *testing.T) {
+		dir := t.TempDir()
+		opt := &option{
+			output:       path.Join(dir, "README.md"),
+			layoutTpl:    "layout: {{block \"content\" .}}{{end}}",
+			itemTemplate: "function/data/item.tpl",
+			itemOutput:   dir + "/{{.filename}}.html",
+		}
+		assert.Nil(t, opt.renderItems([]map[string]interface{}{{
+			"zh": "<b>zh</b>", "en": "en", "filename": "item", "fullpath": "function/data/item.yaml",
+		}}))
+
+		data, err := ioutil.ReadFile(path.Join(dir, "item.html"))
+		assert.Nil(t, err)
+		assert.Equal(t, "# &lt;b&gt;zh&lt;/b&gt;\n\nen", string(data))
+	})
+
+	t.Run("items have the same output", func(t *testing.T) {
+		opt := &option{
+			itemTemplate: "function/data/item.tpl",
+			itemOutput:   t.TempDir() + "/item.md",
+		}
+		assert.NotNil(t, opt.renderItems(items))
+	})
+
+	t.Run("output path out of the directory", func(t *testing.T) {
+		dir := t.TempDir()
+		outside := t.TempDir()
+		assert.Nil(t, os.MkdirAll(path.Join(dir, "docs"), 0755))
+		assert.Nil(t, os.Symlink(outside, path.Join(dir, "docs", "link")))
+
+		for _, filename := range []string{"../item", "link/item", "tools/../../item", ""} {
+			opt := &option{itemTemplate: "function/data/item.tpl", itemOutput: dir + "/docs/{{.filename}}"}
+			assert.NotNil(t, opt.renderItems([]map[string]interface{}{{"filename": filename}}), filename)
+		}
+		opt := &option{itemTemplate: "function/data/item.tpl", itemOutput: "{{.filename}}.md"}
+		assert.NotNil(t, opt.renderItems([]map[string]interface{}{{"filename": outside + "/item"}}))
+
+		assert.NoFileExists(t, path.Join(dir, "item"))
+		assert.NoFileExists(t, path.Join(outside, "item"))
+		assert.NoFileExists(t, path.Join(outside, "item.md"))
+
+		// the subdirectories are fine
+		opt = &option{itemTemplate: "function/data/item.tpl", itemOutput: dir + "/docs/{{.filename}}.md"}
+		assert.Nil(t, opt.renderItems([]map[string]interface{}{{"filename": "tools/item"}}))
+		assert.FileExists(t, path.Join(dir, "docs", "tools", "item.md"))
+	})
+
+	t.Run("missing key in the output path", func(t *testing.T) {
+		opt := &option{
+			itemTemplate: "function/data/item.tpl",
+			itemOutput:   t.TempDir() + "/{{.fake}}.md",
+		}
+		assert.NotNil(t, opt.renderItems(items))
+	})
+
+	t.Run("without item template", func(t *testing.T) {
+		opt := &option{itemOutput: t.TempDir() + "/{{.filename}}.md"}
+		assert.NotNil(t, opt.renderItems(items))
+	})
+}
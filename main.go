@@ -2,54 +2,122 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"github.com/Masterminds/sprig"
 	"github.com/linuxsuren/yaml-readme/function"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
-	"html/template"
 	"io"
 	"io/ioutil"
 	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"text/template"
+	"time"
 )
 
-var logger *log.Logger
+var logger = log.New(os.Stderr, "", log.LstdFlags)
+
+// the exit codes of the command
+const (
+	exitCodeError            = 1
+	exitCodeTemplateNotFound = 2
+	exitCodeRenderFailure    = 3
+)
+
+var (
+	errTemplateNotFound = errors.New("template not found")
+	errRenderFailure    = errors.New("failed to render")
+)
+
+// exitCode returns a distinct exit code for the template not found and render failure errors
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, errTemplateNotFound):
+		return exitCodeTemplateNotFound
+	case errors.Is(err, errRenderFailure):
+		return exitCodeRenderFailure
+	}
+	return exitCodeError
+}
 
 type option struct {
 	pattern       string
 	templateFile  string
+	templateDir   string
+	layout        string
+	layoutTpl     string
+	engine        string
+	output        string
+	delims        string
+	missingKey    string
+	itemTemplate  string
+	itemOutput    string
+	prune         bool
+	pageSize      int
 	includeHeader bool
+	header        string
+	preset        string
 	sortBy        string
 	groupBy       string
+	jobs          int
+	orderBy       string
+	stdin         io.Reader
+	cacheDir      string
+	expandEnv     []string
+
+	allowDefaultTemplate bool
+	// renderDepth is the depth of the nested renderFile
+	renderDepth int
+
+	allowExec   bool
+	execTimeout time.Duration
+	execCache   *execCache
+
+	tocMinDepth int
+	tocMaxDepth int
+	tocOrdered  bool
+
+	helpTimeout   time.Duration
+	helpAllowlist []string
+	strict        bool
 
 	printFunctions bool
+	printFormat    string
 	printVariables bool
 }
 
-func loadMetadata(pattern, groupBy string) (items []map[string]interface{},
+func (o *option) loadMetadata() (items []map[string]interface{},
 	groupData map[string][]map[string]interface{}, err error) {
-	groupData = make(map[string][]map[string]interface{})
-
-	// find YAML files
+	// find YAML files, or read the data from stdin
 	var files []string
-	var data []byte
-	if files, err = filepath.Glob(pattern); err == nil {
-		for _, metaFile := range files {
-			if data, err = ioutil.ReadFile(metaFile); err != nil {
-				logger.Printf("failed to read file [%s], error: %v\n", metaFile, err)
-				continue
-			}
+	if o.pattern == "-" || isRemote(o.pattern) {
+		files = []string{o.pattern}
+	} else if files, err = filepath.Glob(o.pattern); err != nil {
+		return
+	}
+
+	// keep the output stable no matter how the files were found
+	if err = orderFiles(files, o.orderBy); err != nil {
+		return
+	}
+
+	var metadata [][]map[string]interface{}
+	if metadata, err = o.readMetadataFiles(files); err != nil {
+		return
+	}
+	for i, metaMaps := range metadata {
+		metaFile := files[i]
 
-			metaMap := make(map[string]interface{})
-			if err = yaml.Unmarshal(data, metaMap); err != nil {
-				logger.Printf("failed to parse file [%s] as a YAML, error: %v\n", metaFile, err)
+		for _, metaMap := range metaMaps {
+			if metaMap == nil {
 				continue
 			}
 
@@ -67,30 +135,175 @@ func loadMetadata(pattern, groupBy string) (items []map[string]interface{},
 			metaMap["parentname"] = parentname
 			metaMap["fullpath"] = metaFile
 
-			if val, ok := metaMap[groupBy]; ok && val != "" {
-				var strVal string
-				switch val.(type) {
-				case string:
-					strVal = val.(string)
-				case int:
-					strVal = strconv.Itoa(val.(int))
-				}
+			items = append(items, metaMap)
+		}
+	}
+	groupData = groupItems(items, o.groupBy)
+	return
+}
+
+// groupItems groups the items by the value of a field, and keeps the order of the items in each group
+func groupItems(items []map[string]interface{}, groupBy string) (groupData map[string][]map[string]interface{}) {
+	groupData = make(map[string][]map[string]interface{})
+	for _, item := range items {
+		if val, ok := item[groupBy]; ok && val != "" {
+			var strVal string
+			switch val.(type) {
+			case string:
+				strVal = val.(string)
+			case int:
+				strVal = strconv.Itoa(val.(int))
+			}
 
-				if _, ok := groupData[strVal]; ok {
-					groupData[strVal] = append(groupData[strVal], metaMap)
-				} else {
-					groupData[strVal] = []map[string]interface{}{
-						metaMap,
-					}
+			if _, ok := groupData[strVal]; ok {
+				groupData[strVal] = append(groupData[strVal], item)
+			} else {
+				groupData[strVal] = []map[string]interface{}{
+					item,
 				}
 			}
+		}
+	}
+	return
+}
 
-			items = append(items, metaMap)
+// readMetadataFiles reads and parses the files with a bounded number of workers,
+// the result has the same order as the files. An empty item means it cannot be parsed.
+// The error of the first remote data source which cannot be fetched is returned.
+func (o *option) readMetadataFiles(files []string) (result [][]map[string]interface{}, err error) {
+	jobs := o.jobs
+	if jobs < 1 {
+		jobs = 1
+	}
+	result = make([][]map[string]interface{}, len(files))
+	errs := make([]error, len(files))
+
+	indexes := make(chan int)
+	wg := sync.WaitGroup{}
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				result[index], errs[index] = o.readMetadataFile(files[index])
+			}
+		}()
+	}
+
+	for i := range files {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	for _, err = range errs {
+		if err != nil {
+			return
 		}
 	}
 	return
 }
 
+// readMetadataFile reads a file which could be a single item, or a list of items.
+// The file name '-' stands for stdin, and an HTTP(S) URL will be fetched with a local cache.
+// It fails when a URL cannot be fetched and there is no cache, other files which cannot be read are skipped.
+func (o *option) readMetadataFile(metaFile string) (metaMaps []map[string]interface{}, err error) {
+	var data []byte
+	if metaFile == "-" {
+		stdin := o.stdin
+		if stdin == nil {
+			stdin = os.Stdin
+		}
+		data, err = ioutil.ReadAll(stdin)
+	} else if isRemote(metaFile) {
+		if data, err = fetchWithCache(metaFile, o.cacheDir); err != nil {
+			err = fmt.Errorf("failed to fetch the data from %q, error: %v", metaFile, err)
+			return
+		}
+	} else {
+		data, err = ioutil.ReadFile(metaFile)
+	}
+	if err != nil {
+		logger.Printf("failed to read file [%s], error: %v\n", metaFile, err)
+		err = nil
+		return
+	}
+
+	metaMap := make(map[string]interface{})
+	if parseErr := yaml.Unmarshal(data, metaMap); parseErr == nil {
+		metaMaps = []map[string]interface{}{metaMap}
+	} else if listErr := yaml.Unmarshal(data, &metaMaps); listErr != nil {
+		logger.Printf("failed to parse file [%s] as a YAML, error: %v\n", metaFile, parseErr)
+		metaMaps = nil
+	}
+
+	if len(o.expandEnv) > 0 {
+		for _, metaMap := range metaMaps {
+			expandEnv(metaMap, o.expandEnv)
+		}
+	}
+	return
+}
+
+// orderFiles orders the files in place, it uses the natural order by default
+func orderFiles(files []string, orderBy string) (err error) {
+	switch orderBy {
+	case "", "natural":
+		sort.SliceStable(files, func(i, j int) bool {
+			return naturalLess(files[i], files[j])
+		})
+	case "path":
+		sort.Strings(files)
+	case "none":
+	default:
+		err = fmt.Errorf("unsupported order %q, it should be one of path, natural, none", orderBy)
+	}
+	return
+}
+
+// naturalLess compares two strings with treating the digits as numbers,
+// so that item2 is less than item10
+func naturalLess(left, right string) bool {
+	for left != "" && right != "" {
+		leftChunk, leftDigit := nextChunk(left)
+		rightChunk, rightDigit := nextChunk(right)
+		left, right = left[len(leftChunk):], right[len(rightChunk):]
+
+		if leftDigit && rightDigit {
+			leftNum := strings.TrimLeft(leftChunk, "0")
+			rightNum := strings.TrimLeft(rightChunk, "0")
+			if len(leftNum) != len(rightNum) {
+				return len(leftNum) < len(rightNum)
+			}
+			if leftNum != rightNum {
+				return leftNum < rightNum
+			}
+			// take the one with fewer leading zeros as the first one
+			if len(leftChunk) != len(rightChunk) {
+				return len(leftChunk) < len(rightChunk)
+			}
+		} else if leftChunk != rightChunk {
+			return leftChunk < rightChunk
+		}
+	}
+	return len(left) < len(right)
+}
+
+// nextChunk returns the leading digits or non-digits of a string
+func nextChunk(text string) (chunk string, digit bool) {
+	digit = isDigit(text[0])
+	end := 1
+	for end < len(text) && isDigit(text[end]) == digit {
+		end++
+	}
+	chunk = text[:end]
+	return
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
 func sortMetadata(items []map[string]interface{}, sortByField string) {
 	descending := true
 	if strings.HasPrefix(sortByField, "!") {
@@ -100,32 +313,46 @@ func sortMetadata(items []map[string]interface{}, sortByField string) {
 	sortBy(items, sortByField, descending)
 }
 
-func loadTemplate(templateFile string, includeHeader bool) (readmeTpl string, err error) {
+func loadTemplate(templateFile string) (readmeTpl string, err error) {
 	// load readme template
 	var data []byte
-	if data, err = ioutil.ReadFile(templateFile); err != nil {
-		fmt.Printf("failed to load README template, error: %v\n", err)
-		err = nil
-		readmeTpl = `|中文名称|英文名称|JD|
-|---|---|---|
-{{- range $val := .}}
-|{{$val.zh}}|{{$val.en}}|{{$val.jd}}|
-{{- end}}`
-	}
-	if includeHeader {
-		readmeTpl = fmt.Sprintf("> This file was generated by [%s](%s) via [yaml-readme](https://github.com/LinuxSuRen/yaml-readme), please don't edit it directly!\n\n",
-			filepath.Base(templateFile), filepath.Base(templateFile))
-	}
-	readmeTpl = readmeTpl + string(data)
-	s, err := regexp.Compile("#!yaml-readme .*\n")
-	readmeTpl = s.ReplaceAllString(readmeTpl, "")
+	if data, err = ioutil.ReadFile(templateFile); err == nil {
+		readmeTpl = removeDirective(string(data))
+	}
 	return
 }
 
+// loadLayout loads the layout template, a relative path is relative to the template file
+func (o *option) loadLayout() (layoutTpl string, err error) {
+	layoutFile := o.layout
+	if !filepath.IsAbs(layoutFile) {
+		layoutFile = filepath.Join(filepath.Dir(o.templateFile), layoutFile)
+	}
+
+	var data []byte
+	if data, err = ioutil.ReadFile(layoutFile); err == nil {
+		layoutTpl = removeDirective(string(data))
+	}
+	return
+}
+
+var directivePattern = regexp.MustCompile("#!yaml-readme .*\n")
+
+// removeDirective removes the metadata line which starts with '#!yaml-readme'
+func removeDirective(tplContent string) string {
+	return directivePattern.ReplaceAllString(tplContent, "")
+}
+
 func (o *option) runE(cmd *cobra.Command, args []string) (err error) {
 	logger = log.New(cmd.ErrOrStderr(), "", log.LstdFlags)
+	o.stdin = cmd.InOrStdin()
+	if err = applyDirective(cmd.Flags(), o.templateFile); err != nil {
+		err = fmt.Errorf("failed to parse the directive of %q, error: %v", o.templateFile, err)
+		return
+	}
+
 	if o.printFunctions {
-		printFunctions(cmd.OutOrStdout())
+		err = printFunctions(cmd.OutOrStdout(), o.printFormat)
 		return
 	}
 
@@ -137,8 +364,8 @@ func (o *option) runE(cmd *cobra.Command, args []string) (err error) {
 	// load metadata from YAML files
 	var items []map[string]interface{}
 	var groupData map[string][]map[string]interface{}
-	if items, groupData, err = loadMetadata(o.pattern, o.groupBy); err != nil {
-		err = fmt.Errorf("failed to load metadat from %q", o.pattern)
+	if items, groupData, err = o.loadMetadata(); err != nil {
+		err = fmt.Errorf("failed to load metadat from %q, error: %v", o.pattern, err)
 		return
 	}
 
@@ -148,35 +375,185 @@ func (o *option) runE(cmd *cobra.Command, args []string) (err error) {
 
 	// load readme template
 	var readmeTpl string
-	if readmeTpl, err = loadTemplate(o.templateFile, o.includeHeader); err != nil {
-		err = fmt.Errorf("failed to load template file from %q", o.templateFile)
+	if o.preset != "" {
+		readmeTpl, err = o.generatePreset(o.preset, items)
+	} else if readmeTpl, err = loadTemplate(o.templateFile); os.IsNotExist(err) {
+		if !o.allowDefaultTemplate {
+			err = fmt.Errorf("%w: %q, please create it or use --preset, --allow-default-template", errTemplateNotFound, o.templateFile)
+			return
+		}
+		logger.Printf("failed to load README template, use the default one instead, error: %v\n", err)
+		readmeTpl, err = o.generatePreset(defaultPreset(o.groupBy), items)
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to load template file from %q, error: %v", o.templateFile, err)
 		return
 	}
+	if o.layout != "" {
+		if o.layoutTpl, err = o.loadLayout(); os.IsNotExist(err) {
+			err = fmt.Errorf("%w: failed to load layout from %q, error: %v", errTemplateNotFound, o.layout, err)
+			return
+		} else if err != nil {
+			err = fmt.Errorf("failed to load layout from %q, error: %v", o.layout, err)
+			return
+		}
+	}
 
-	// render it with grouped data
-	if o.groupBy != "" {
-		err = renderTemplate(readmeTpl, groupData, cmd.OutOrStdout())
+	if o.pageSize > 0 {
+		// split the items into pages
+		err = o.renderPages(readmeTpl, items)
 	} else {
-		err = renderTemplate(readmeTpl, items, cmd.OutOrStdout())
+		// render it with grouped data
+		buf := bytes.NewBuffer([]byte{})
+		if o.groupBy != "" {
+			err = o.renderTemplate(readmeTpl, groupData, buf)
+		} else {
+			err = o.renderTemplate(readmeTpl, items, buf)
+		}
+		var output []byte
+		if err == nil {
+			output, err = o.addHeader(o.templateFile, o.output, buf.Bytes())
+		}
+		if err == nil {
+			err = o.writeOutput(cmd.OutOrStdout(), output)
+		}
+	}
+
+	// render a page for each item
+	if err == nil && o.itemOutput != "" {
+		err = o.renderItems(items)
 	}
 	return
 }
 
-func renderTemplateToString(tplContent string, object interface{}) (output string, err error) {
+// writeOutput writes the data into the output file, or stdout if there is no output file
+func (o *option) writeOutput(stdout io.Writer, data []byte) (err error) {
+	if o.output == "" {
+		_, err = stdout.Write(data)
+	} else {
+		err = writeFile(o.output, data)
+	}
+	return
+}
+
+func (o *option) renderTemplateToString(tplContent string, object interface{}) (output string, err error) {
 	buf := bytes.NewBuffer([]byte{})
-	if err = renderTemplate(tplContent, object, buf); err == nil {
+	if err = o.renderTemplate(tplContent, object, buf); err == nil {
 		output = buf.String()
 	}
 	return
 }
 
-func renderTemplate(tplContent string, object interface{}, writer io.Writer) (err error) {
-	var tpl *template.Template
-	if tpl, err = template.New("readme").
-		Funcs(getFuncMap(tplContent)).
-		Funcs(sprig.FuncMap()).Parse(tplContent); err == nil {
-		err = tpl.Execute(writer, object)
+func (o *option) renderTemplate(tplContent string, object interface{}, writer io.Writer) (err error) {
+	var engine string
+	if engine, err = o.getEngine(); err != nil {
+		return
+	}
+
+	var leftDelim, rightDelim string
+	if leftDelim, rightDelim, err = o.getDelims(); err != nil {
+		return
+	}
+
+	var missingKey string
+	if missingKey, err = o.getMissingKeyOption(); err != nil {
+		return
+	}
+
+	var toc tocOption
+	if toc, err = o.getTOCOption(); err != nil {
+		return
+	}
+
+	var help helpOption
+	if help, err = o.getHelpOption(); err != nil {
+		return
+	}
+
+	var runner *commandRunner
+	if o.allowExec {
+		if runner, err = o.getCommandRunner(); err != nil {
+			return
+		}
+	}
+
+	// the invalid options are general errors, and the errors of parsing or executing the template are render failures
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("%w: %v", errRenderFailure, err)
+		}
+	}()
+
+	var executor templateExecutor
+	tpl := template.New("readme").Delims(leftDelim, rightDelim).Option(missingKey)
+
+	funcMap := getFuncMap(o.layoutTpl + tplContent)
+	funcMap["include"] = func(name string, data interface{}) (output string, err error) {
+		buf := bytes.NewBuffer([]byte{})
+		if err = executor.ExecuteTemplate(buf, name, data); err == nil {
+			output = buf.String()
+		}
+		return
+	}
+	funcMap["printHelp"] = func(cmd string, args ...string) (string, error) {
+		return printHelp(help, cmd, args...)
 	}
+	funcMap["printCommands"] = func(cmd string, args ...string) (string, error) {
+		return printCommands(help, cmd, args...)
+	}
+	scope := newFileScope(o.templateFile)
+	funcMap["snippet"] = func(path, region string) (string, error) {
+		return snippet(scope, path, region)
+	}
+	funcMap["readFile"] = scope.readFile
+	funcMap["includeMarkdown"] = scope.includeMarkdown
+	funcMap["goModules"] = scope.goModules
+	funcMap["renderFile"] = o.renderFile
+	if runner != nil {
+		funcMap["exec"] = runner.exec
+		funcMap["execWith"] = runner.execWith
+	}
+	// the TOC is filled after rendering, so the headings which come from the items are listed
+	funcMap["printToc"] = func() string {
+		return tocPlaceholder
+	}
+	if engine == "html" {
+		funcMap = trustFuncs(funcMap)
+	}
+	for name, fn := range sprig.TxtFuncMap() {
+		funcMap[name] = fn
+	}
+	tpl.Funcs(funcMap)
+
+	if err = loadPartials(tpl, o.templateDir, o.templateFile); err != nil {
+		return
+	}
+	if o.layoutTpl != "" {
+		// the template is the content of the layout, and it could override any blocks of the layout
+		if _, err = tpl.Parse(o.layoutTpl); err == nil {
+			_, err = tpl.New("content").Parse(tplContent)
+		}
+	} else {
+		_, err = tpl.Parse(tplContent)
+	}
+	if err != nil {
+		return
+	}
+
+	executor = tpl
+	if engine == "html" {
+		var htmlTpl templateExecutor
+		if htmlTpl, err = toHTMLTemplate(tpl, funcMap, missingKey); err != nil {
+			return
+		}
+		executor = htmlTpl
+	}
+	output := bytes.NewBuffer([]byte{})
+	if err = executor.Execute(output, object); err != nil {
+		err = explainMissingKey(err, object)
+		return
+	}
+	_, err = io.WriteString(writer, fillTOC(output.String(), toc))
 	return
 }
 
@@ -186,37 +563,45 @@ parentname
 fullpath`))
 }
 
-func printFunctions(stdout io.Writer) {
-	funcMap := getFuncMap("")
-	var funcs []string
-	for k := range funcMap {
-		funcs = append(funcs, k)
-	}
-	sort.SliceStable(funcs, func(i, j int) bool {
-		return strings.Compare(funcs[i], funcs[j]) < 0
-	})
-	_, _ = stdout.Write([]byte(strings.Join(funcs, "\n")))
-}
-
 func getFuncMap(readmeTpl string) template.FuncMap {
 	return template.FuncMap{
-		"printHelp": func(cmd string) (output string) {
-			var err error
-			var data []byte
-			if data, err = exec.Command(cmd, "--help").Output(); err != nil {
-				_, _ = fmt.Fprintln(os.Stderr, "failed to run command", cmd)
-			} else {
-				output = fmt.Sprintf(`%s
-%s
-%s`, "```shell", string(data), "```")
-			}
-			return
+		// include is a placeholder, it will be replaced with the one which can access all the templates
+		"include": func(name string, data interface{}) (string, error) {
+			return "", fmt.Errorf("no template %q", name)
+		},
+		"printHelp": func(cmd string, args ...string) (string, error) {
+			return printHelp(defaultHelpOption, cmd, args...)
+		},
+		"printCommands": func(cmd string, args ...string) (string, error) {
+			return printCommands(defaultHelpOption, cmd, args...)
+		},
+		"snippet": func(path, region string) (string, error) {
+			return snippet(newFileScope(""), path, region)
+		},
+		"readFile": func(path string) (string, error) {
+			return newFileScope("").readFile(path)
+		},
+		"includeMarkdown": func(path string, shift int) (string, error) {
+			return newFileScope("").includeMarkdown(path, shift)
+		},
+		"goModules": func(path string) (*goModule, error) {
+			return newFileScope("").goModules(path)
+		},
+		"exec": func(cmd string, args ...string) (string, error) {
+			return "", errExecDisabled
+		},
+		"execWith": func(options interface{}, cmd string, args ...string) (string, error) {
+			return "", errExecDisabled
+		},
+		// renderFile is a placeholder, it will be replaced with the one which has the options of the current template
+		"renderFile": func(path string, data interface{}) (string, error) {
+			return "", fmt.Errorf("cannot render %q without a template", path)
 		},
 		"printToc": func() string {
-			return generateTOC(readmeTpl)
+			return generateTOC(readmeTpl, defaultTOCOption)
 		},
-		"printContributors": func(owner, repo string) template.HTML {
-			return template.HTML(function.PrintContributors(owner, repo))
+		"printContributors": func(owner, repo string) string {
+			return function.PrintContributors(owner, repo)
 		},
 		"printStarHistory": func(owner, repo string) string {
 			return printStarHistory(owner, repo)
@@ -260,34 +645,6 @@ func sortBy(items []map[string]interface{}, sortBy string, descending bool) {
 	})
 }
 
-func generateTOC(txt string) (toc string) {
-	items := strings.Split(txt, "\n")
-	for i := range items {
-		item := items[i]
-
-		var prefix string
-		var tag string
-		if strings.HasPrefix(item, "## ") {
-			tag = strings.TrimPrefix(item, "## ")
-			prefix = "- "
-		} else if strings.HasPrefix(item, "### ") {
-			tag = strings.TrimPrefix(item, "### ")
-			prefix = " - "
-		} else {
-			continue
-		}
-
-		// not support those titles which have whitespaces
-		tag = strings.TrimSpace(tag)
-		if len(strings.Split(tag, " ")) > 1 {
-			continue
-		}
-
-		toc = toc + fmt.Sprintf("%s[%s](#%s)\n", prefix, tag, strings.ToLower(tag))
-	}
-	return
-}
-
 func printStarHistory(owner, repo string) string {
 	return fmt.Sprintf(`[![Star History Chart](https://api.star-history.com/svg?repos=%[1]s/%[2]s&type=Date)](https://star-history.com/#%[1]s/%[2]s&Date)`,
 		owner, repo)
@@ -317,19 +674,81 @@ Some functions rely on the GitHub API, in order to avoid X-RateLimit-Limit error
 		RunE: opt.runE,
 	}
 	cmd.SetOut(os.Stdout)
+	cmd.AddCommand(newInitCommand())
 	flags := cmd.Flags()
 	flags.StringVarP(&opt.pattern, "pattern", "p", "items/*.yaml",
-		"The glob pattern with Golang spec to find files")
+		"The glob pattern with Golang spec to find files, '-' to read from stdin, or an HTTP(S) URL. "+
+			"A file could be a single item, or a list of items")
 	flags.StringVarP(&opt.templateFile, "template", "t", "README.tpl",
 		"The template file which should follow Golang template spec")
+	flags.StringVarP(&opt.preset, "preset", "", "",
+		"Generate the template from the fields of the items instead of the template file, "+
+			"it could be table, list, cards, grouped-table")
+	flags.BoolVarP(&opt.allowDefaultTemplate, "allow-default-template", "", false,
+		"Render the items with the default preset instead of failing when the template file does not exist")
+	flags.StringVarP(&opt.templateDir, "template-dir", "", "",
+		"The directory of the shared templates, all the *.tpl files in it could be used by "+
+			`{{template "partials/row.tpl" .}} or {{include "partials/row.tpl" .}}`)
+	flags.StringVarP(&opt.layout, "layout", "", "",
+		`The layout template which is relative to the template file, the template will be rendered as its {{block "content" .}}. `+
+			"It could be set in the directive of the template as well. For example: #!yaml-readme --layout base.tpl")
+	flags.StringVarP(&opt.output, "output", "o", "",
+		"The file to write the result, it writes to stdout by default")
+	flags.StringVarP(&opt.engine, "engine", "", "",
+		"The template engine, it could be text or html. The html engine escapes the values. "+
+			"It is html for the *.html output file, and text for others by default")
+	flags.StringVarP(&opt.delims, "delims", "", "",
+		"The left and right delimiters of the template which are separated by a comma. For example: --delims '[[,]]'")
+	flags.StringVarP(&opt.missingKey, "missing-key", "", "default",
+		"The behavior when a key is missing in an item, it could be error, zero, default. "+
+			"The error one stops rendering and reports the item which misses the key")
+	flags.StringVarP(&opt.itemTemplate, "item-template", "", "",
+		"The template file to render a page for each item, it works with --item-output")
+	flags.StringVarP(&opt.itemOutput, "item-output", "", "",
+		"The output path template of each item page. For example: --item-output 'docs/tools/{{.filename}}.md'")
+	flags.IntVarP(&opt.pageSize, "page-size", "", 0,
+		"Split the items into pages with this size, the pages are written as page-2.md, page-3.md beside the output file")
+	flags.BoolVarP(&opt.prune, "prune", "", false,
+		"Remove the stale item pages or pages which were generated by yaml-readme before but not this time")
 	flags.BoolVarP(&opt.includeHeader, "include-header", "", true,
 		"Indicate if include a notice header on the top of the README file")
+	flags.StringVarP(&opt.header, "header", "", "quote",
+		"The notice header, it could be quote, comment (an invisible HTML comment), or a custom template. "+
+			"The available variables of the template are .Template, .Pattern, .Hash")
 	flags.StringVarP(&opt.sortBy, "sort-by", "", "",
 		"Sort the array data descending by which field, or sort it ascending with the prefix '!'. For example: --sort-by !year")
 	flags.StringVarP(&opt.groupBy, "group-by", "", "",
 		"Group the array data by which field")
+	flags.IntVarP(&opt.jobs, "jobs", "j", runtime.NumCPU(),
+		"The number of workers to read and parse the item files concurrently")
+	flags.StringVarP(&opt.cacheDir, "cache-dir", "", defaultCacheDir(),
+		"The directory to cache the data from HTTP(S) URLs")
+	flags.StringSliceVarP(&opt.expandEnv, "expand-env", "", nil,
+		"The allowlist of environment variables to expand as ${VAR} or ${VAR:-default} in the item values. "+
+			"Nothing will be expanded by default")
+	flags.StringVarP(&opt.orderBy, "order-by", "", "natural",
+		"The default order of the items before sorting, it could be path, natural, none. "+
+			"The natural order puts item2 before item10")
+	flags.IntVarP(&opt.tocMinDepth, "toc-min-depth", "", defaultTOCOption.minDepth,
+		"The minimum level of the headings which are listed by printToc")
+	flags.IntVarP(&opt.tocMaxDepth, "toc-max-depth", "", defaultTOCOption.maxDepth,
+		"The maximum level of the headings which are listed by printToc")
+	flags.BoolVarP(&opt.tocOrdered, "toc-ordered", "", false,
+		"Indicate if printToc prints an ordered list instead of a bulleted list")
+	flags.DurationVarP(&opt.helpTimeout, "help-timeout", "", defaultHelpOption.timeout,
+		"The timeout of running a command by printHelp or printCommands")
+	flags.StringSliceVarP(&opt.helpAllowlist, "help-allowlist", "", nil,
+		"The allowlist of the commands which could be run by printHelp or printCommands. All commands are allowed by default")
+	flags.BoolVarP(&opt.strict, "strict", "", false,
+		"Fail the rendering when a command of printHelp or printCommands is missing or fails, instead of printing nothing")
+	flags.BoolVarP(&opt.allowExec, "allow-exec", "", false,
+		"Allow the exec functions to run commands. It cannot be set in the directive of the template")
+	flags.DurationVarP(&opt.execTimeout, "exec-timeout", "", defaultExecTimeout,
+		"The default timeout of running a command by the exec functions")
 	flags.BoolVarP(&opt.printFunctions, "print-functions", "", false,
 		"Print all the functions and exit")
+	flags.StringVarP(&opt.printFormat, "print-format", "", "name",
+		"The format of --print-functions, it could be name, table (Markdown), json")
 	flags.BoolVarP(&opt.printVariables, "print-variables", "", false,
 		"Print all the variables and exit")
 	return
@@ -337,6 +756,6 @@ Some functions rely on the GitHub API, in order to avoid X-RateLimit-Limit error
 
 func main() {
 	if err := newRootCommand().Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
@@ -2,8 +2,11 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path"
 	"reflect"
 	"testing"
 )
@@ -78,47 +81,6 @@ func Test_sortBy(t *testing.T) {
 	}
 }
 
-func Test_generateTOC(t *testing.T) {
-	type args struct {
-		txt string
-	}
-	tests := []struct {
-		name    string
-		args    args
-		wantToc string
-	}{{
-		name: "simple text",
-		args: args{
-			txt: `## Good`,
-		},
-		wantToc: `- [Good](#good)
-`,
-	}, {
-		name: "multiple levels of the titles",
-		args: args{
-			txt: `## Good
-content
-### Better`,
-		},
-		wantToc: `- [Good](#good)
- - [Better](#better)
-`,
-	}, {
-		name: "has whitespace between title",
-		args: args{
-			txt: `## Good
-## This is good`,
-		},
-		wantToc: `- [Good](#good)
-`,
-	}}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			assert.Equalf(t, tt.wantToc, generateTOC(tt.args.txt), "generateTOC(%v)", tt.args.txt)
-		})
-	}
-}
-
 func Test_printStarHistory(t *testing.T) {
 	type args struct {
 		owner string
@@ -152,7 +114,7 @@ func Test_getFuncMap(t *testing.T) {
 	assert.NotNil(t, funcMap["printVisitorCount"])
 
 	buf := bytes.NewBuffer([]byte{})
-	printFunctions(buf)
+	assert.Nil(t, printFunctions(buf, "name"))
 	for k, val := range funcMap {
 		assert.Contains(t, buf.String(), k)
 		assert.NotNil(t, val)
@@ -177,7 +139,12 @@ func Test_getFuncMap(t *testing.T) {
 
 		reflect.ValueOf(val).Call(params)
 
-		assert.Equal(t, reflect.String, valType.Out(0).Kind())
+		// the functions return strings, except the ones which return structured data
+		if k == "goModules" {
+			assert.Equal(t, reflect.Ptr, valType.Out(0).Kind())
+		} else {
+			assert.Equal(t, reflect.String, valType.Out(0).Kind())
+		}
 		if numOut == 2 {
 			assert.Equal(t, reflect.Interface, valType.Out(1).Kind())
 		}
@@ -253,10 +220,23 @@ func Test_renderTemplateToString(t *testing.T) {
 		wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
 			return false
 		},
+	}, {
+		name: "TOC of the headings from the items",
+		args: args{
+			tplContent: `{{printToc}}
+{{range $key, $val := .}}
+## {{$key}}
+{{end}}`,
+			object: map[string][]map[string]interface{}{"2021": nil, "2022": nil},
+		},
+		wantOutput: "- [2021](#2021)\n- [2022](#2022)\n\n\n## 2021\n\n## 2022\n",
+		wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+			return false
+		},
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotOutput, err := renderTemplateToString(tt.args.tplContent, tt.args.object)
+			gotOutput, err := (&option{}).renderTemplateToString(tt.args.tplContent, tt.args.object)
 			if !tt.wantErr(t, err, fmt.Sprintf("renderTemplateToString(%v, %v)", tt.args.tplContent, tt.args.object)) {
 				return
 			}
@@ -267,7 +247,7 @@ func Test_renderTemplateToString(t *testing.T) {
 
 func Test_newRootCommand(t *testing.T) {
 	cmd := newRootCommand()
-	flags := []string{"pattern", "template", "include-header", "sort-by", "group-by", "print-functions", "print-variables"}
+	flags := []string{"pattern", "template", "preset", "allow-default-template", "template-dir", "layout", "output", "engine", "delims", "missing-key", "item-template", "item-output", "page-size", "prune", "include-header", "header", "sort-by", "group-by", "jobs", "order-by", "cache-dir", "expand-env", "toc-min-depth", "toc-max-depth", "toc-ordered", "help-timeout", "help-allowlist", "strict", "allow-exec", "exec-timeout", "print-functions", "print-format", "print-variables"}
 	for _, flag := range flags {
 		assert.NotNil(t, cmd.Flag(flag))
 	}
@@ -277,6 +257,7 @@ func TestCommand(t *testing.T) {
 	tests := []struct {
 		name         string
 		flags        []string
+		stdin        string
 		hasError     bool
 		expectOutput string
 	}{{
@@ -287,26 +268,9 @@ func TestCommand(t *testing.T) {
 parentname
 fullpath`,
 	}, {
-		name:     "print functions",
-		flags:    []string{"--print-functions"},
-		hasError: false,
-		expectOutput: `gh
-ghEmoji
-ghID
-ghs
-gstatic
-link
-linkOrEmpty
-printContributors
-printGHTable
-printHelp
-printPages
-printStarHistory
-printToc
-printVisitorCount
-render
-twitterLink
-youTubeLink`,
+		name:     "print functions with an unknown format",
+		flags:    []string{"--print-functions", "--print-format", "fake"},
+		hasError: true,
 	}, {
 		name:     "normal case",
 		flags:    []string{"--template", "function/data/README.tpl", "--pattern", "function/data/*.yaml", "--sort-by", "zh"},
@@ -347,12 +311,121 @@ Year: zh
 | zh | en |
 | zh | en |
 `,
+	}, {
+		name:  "read items from stdin",
+		flags: []string{"--template", "function/data/README.tpl", "--pattern", "-", "--include-header=false"},
+		stdin: `- zh: zh
+  en: en
+  jd: jd
+- zh: zh-2
+  en: en-2
+  jd: jd-2`,
+		hasError: false,
+		expectOutput: `|中文名称|英文名称|JD|
+|---|---|---|
+|zh|en|jd|
+|zh-2|en-2|jd-2|`,
+	}, {
+		name:  "expand environment variables",
+		flags: []string{"--template", "function/data/README.tpl", "--pattern", "-", "--include-header=false", "--expand-env", "YAML_README_EN,YAML_README_JD"},
+		stdin: `- zh: ${YAML_README_ZH}
+  en: ${YAML_README_EN}
+  jd: ${YAML_README_JD:-jd}`,
+		hasError: false,
+		expectOutput: `|中文名称|英文名称|JD|
+|---|---|---|
+|${YAML_README_ZH}|en|jd|`,
+	}, {
+		name: "shared templates",
+		flags: []string{"--template", "function/data/templates/README.tpl", "--template-dir", "function/data/templates",
+			"--pattern", "function/data/*.yaml", "--include-header=false"},
+		hasError: false,
+		expectOutput: `| Zh | En |
+|---|---|
+| zh | en |
+| zh | en |
+
+> Total: 2`,
+	}, {
+		name:     "render within a layout",
+		flags:    []string{"--template", "function/data/layout/README.tpl"},
+		hasError: false,
+		expectOutput: `> This file was generated by [README.tpl](README.tpl) via [yaml-readme](https://github.com/LinuxSuRen/yaml-readme), please don't edit it directly!
+
+# Items
+
+
+| Zh | En |
+|---|---|
+| zh | en |
+| zh | en |
+
+> Total: 2`,
+	}, {
+		name:     "layout does not exist",
+		flags:    []string{"--template", "function/data/README.tpl", "--layout", "fake.tpl"},
+		hasError: true,
+	}, {
+		name:     "custom delimiters in the directive",
+		flags:    []string{"--template", "function/data/README-delims.tpl", "--pattern", "function/data/*.yaml"},
+		hasError: false,
+		expectOutput: `> This file was generated by [README-delims.tpl](README-delims.tpl) via [yaml-readme](https://github.com/LinuxSuRen/yaml-readme), please don't edit it directly!
+
+- [Usage](#usage)
+
+## Usage
+` + "```yaml" + `
+steps:
+  - run: echo ${{ github.sha }}
+` + "```" + `
+| zh |
+| zh |`,
+	}, {
+		name:     "invalid delimiters",
+		flags:    []string{"--template", "function/data/README.tpl", "--delims", "[["},
+		hasError: true,
+	}, {
+		name:     "the template file does not exist",
+		flags:    []string{"--template", "fake.tpl", "--pattern", "function/data/*.yaml"},
+		hasError: true,
+	}, {
+		name:     "default template when the template file does not exist",
+		flags:    []string{"--template", "fake.tpl", "--pattern", "function/data/*.yaml", "--include-header=false", "--allow-default-template"},
+		hasError: false,
+		expectOutput: `| En | Jd | Year | Zh |
+|---|---|---|---|
+| en | jd | 2022 | zh |
+| en | jd | 2021 | zh |`,
+	}, {
+		name:     "grouped table preset",
+		flags:    []string{"--preset", "grouped-table", "--pattern", "function/data/*.yaml", "--group-by", "year", "--include-header=false"},
+		hasError: false,
+		expectOutput: `
+
+## 2021
+
+| En | Jd | Zh |
+|---|---|---|
+| en | jd | zh |
+
+## 2022
+
+| En | Jd | Zh |
+|---|---|---|
+| en | jd | zh |`,
+	}, {
+		name:     "unknown preset",
+		flags:    []string{"--preset", "fake", "--pattern", "function/data/*.yaml"},
+		hasError: true,
 	}}
+	t.Setenv("YAML_README_ZH", "zh")
+	t.Setenv("YAML_README_EN", "en")
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			cmd := newRootCommand()
 			buf := bytes.NewBuffer([]byte{})
 			cmd.SetOut(buf)
+			cmd.SetIn(bytes.NewBufferString(tt.stdin))
 			cmd.SetArgs(tt.flags)
 
 			err := cmd.Execute()
@@ -367,6 +440,57 @@ Year: zh
 	}
 }
 
+func Test_exitCode(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		expect int
+	}{{
+		name:   "template not found",
+		err:    fmt.Errorf("%w: fake.tpl", errTemplateNotFound),
+		expect: exitCodeTemplateNotFound,
+	}, {
+		name:   "render failure",
+		err:    fmt.Errorf("%w: template: readme:1: bad", errRenderFailure),
+		expect: exitCodeRenderFailure,
+	}, {
+		name:   "other errors",
+		err:    errors.New("fake"),
+		expect: exitCodeError,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, exitCode(tt.err))
+		})
+	}
+
+	// the render failures of the item pages and the pages keep the exit code
+	dir := t.TempDir()
+	assert.Nil(t, ioutil.WriteFile(path.Join(dir, "item.tpl"), []byte(`{{fail "bad"}}`), 0644))
+	items := []map[string]interface{}{{"filename": "item", "fullpath": "item.yaml"}}
+
+	err := (&option{itemTemplate: path.Join(dir, "item.tpl"), itemOutput: dir + "/{{.filename}}.md"}).renderItems(items)
+	assert.Equal(t, exitCodeRenderFailure, exitCode(err))
+	err = (&option{output: path.Join(dir, "README.md"), pageSize: 1}).renderPages(`{{fail "bad"}}`, items)
+	assert.Equal(t, exitCodeRenderFailure, exitCode(err))
+
+	// the invalid flags are general errors
+	for _, flags := range [][]string{
+		{"--delims", "[["},
+		{"--engine", "fake"},
+		{"--missing-key", "fake"},
+		{"--toc-min-depth", "5", "--toc-max-depth", "2"},
+		{"--help-timeout", "-1s"},
+		{"--allow-exec", "--exec-timeout", "-1s"},
+	} {
+		cmd := newRootCommand()
+		cmd.SetOut(bytes.NewBuffer([]byte{}))
+		cmd.SetErr(bytes.NewBuffer([]byte{}))
+		cmd.SetArgs(append([]string{"--template", "function/data/README.tpl", "--pattern", "function/data/*.yaml"}, flags...))
+		assert.Equal(t, exitCodeError, exitCode(cmd.Execute()), flags)
+	}
+}
+
 func Test_sortMetadata(t *testing.T) {
 	type args struct {
 		items       []map[string]interface{}
@@ -420,8 +544,7 @@ func Test_sortMetadata(t *testing.T) {
 
 func Test_loadTemplate(t *testing.T) {
 	type args struct {
-		templateFile  string
-		includeHeader bool
+		templateFile string
 	}
 	tests := []struct {
 		name          string
@@ -431,8 +554,7 @@ func Test_loadTemplate(t *testing.T) {
 	}{{
 		name: "normal case",
 		args: args{
-			templateFile:  "function/data/README.tpl",
-			includeHeader: false,
+			templateFile: "function/data/README.tpl",
 		},
 		wantReadmeTpl: func() string {
 			data, _ := ioutil.ReadFile("function/data/README.tpl")
@@ -445,38 +567,19 @@ func Test_loadTemplate(t *testing.T) {
 	}, {
 		name: "fake file",
 		args: args{
-			templateFile:  "fake",
-			includeHeader: false,
-		},
-		wantReadmeTpl: func() string {
-			data, _ := ioutil.ReadFile("function/data/README.tpl")
-			return string(data)
-		},
-		wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
-			assert.Nil(t, err)
-			return true
-		},
-	}, {
-		name: "include header",
-		args: args{
-			templateFile:  "function/data/README.tpl",
-			includeHeader: true,
+			templateFile: "fake",
 		},
 		wantReadmeTpl: func() string {
-			data, _ := ioutil.ReadFile("function/data/README.tpl")
-			return `> This file was generated by [README.tpl](README.tpl) via [yaml-readme](https://github.com/LinuxSuRen/yaml-readme), please don't edit it directly!
-
-` + string(data)
+			return ""
 		},
 		wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
-			assert.Nil(t, err)
+			assert.True(t, os.IsNotExist(err))
 			return true
 		},
 	}, {
 		name: "has metadata",
 		args: args{
-			templateFile:  "function/data/README-with-metadata.tpl",
-			includeHeader: false,
+			templateFile: "function/data/README-with-metadata.tpl",
 		},
 		wantReadmeTpl: func() string {
 			return `a fake template`
@@ -488,11 +591,11 @@ func Test_loadTemplate(t *testing.T) {
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotReadmeTpl, err := loadTemplate(tt.args.templateFile, tt.args.includeHeader)
-			if !tt.wantErr(t, err, fmt.Sprintf("loadTemplate(%v, %v)", tt.args.templateFile, tt.args.includeHeader)) {
+			gotReadmeTpl, err := loadTemplate(tt.args.templateFile)
+			if !tt.wantErr(t, err, fmt.Sprintf("loadTemplate(%v)", tt.args.templateFile)) {
 				return
 			}
-			assert.Equalf(t, tt.wantReadmeTpl(), gotReadmeTpl, "loadTemplate(%v, %v)", tt.args.templateFile, tt.args.includeHeader)
+			assert.Equalf(t, tt.wantReadmeTpl(), gotReadmeTpl, "loadTemplate(%v)", tt.args.templateFile)
 		})
 	}
 }
@@ -501,6 +604,7 @@ func Test_loadMetadata(t *testing.T) {
 	type args struct {
 		pattern string
 		groupBy string
+		jobs    int
 	}
 	tests := []struct {
 		name          string
@@ -531,10 +635,58 @@ func Test_loadMetadata(t *testing.T) {
 			assert.Nil(t, err)
 			return true
 		},
+	}, {
+		name: "concurrent workers",
+		args: args{
+			pattern: "function/data/*.yaml",
+			groupBy: "year",
+			jobs:    4,
+		},
+		wantItems: []map[string]interface{}{{
+			"en": "en", "filename": "item-2022", "fullpath": "function/data/item-2022.yaml", "jd": "jd", "parentname": "data", "zh": "zh", "year": 2022,
+		}, {
+			"en": "en", "filename": "item", "fullpath": "function/data/item.yaml", "jd": "jd", "parentname": "data", "zh": "zh", "year": 2021,
+		}},
+		wantGroupData: map[string][]map[string]interface{}{
+			"2021": {{
+				"en": "en", "filename": "item", "fullpath": "function/data/item.yaml", "jd": "jd", "parentname": "data", "zh": "zh", "year": 2021,
+			}},
+			"2022": {{
+				"en": "en", "filename": "item-2022", "fullpath": "function/data/item-2022.yaml", "jd": "jd", "parentname": "data", "zh": "zh", "year": 2022,
+			}},
+		},
+		wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+			assert.Nil(t, err)
+			return true
+		},
+	}, {
+		name: "a list of items in one file",
+		args: args{
+			pattern: "function/data/list/*.json",
+			groupBy: "year",
+		},
+		wantItems: []map[string]interface{}{{
+			"en": "en", "filename": "items", "fullpath": "function/data/list/items.json", "parentname": "list", "zh": "zh", "year": 2021,
+		}, {
+			"en": "en", "filename": "items", "fullpath": "function/data/list/items.json", "parentname": "list", "zh": "zh", "year": 2022,
+		}},
+		wantGroupData: map[string][]map[string]interface{}{
+			"2021": {{
+				"en": "en", "filename": "items", "fullpath": "function/data/list/items.json", "parentname": "list", "zh": "zh", "year": 2021,
+			}},
+			"2022": {{
+				"en": "en", "filename": "items", "fullpath": "function/data/list/items.json", "parentname": "list", "zh": "zh", "year": 2022,
+			}},
+		},
+		wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+			assert.Nil(t, err)
+			return true
+		},
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotItems, gotGroupData, err := loadMetadata(tt.args.pattern, tt.args.groupBy)
+			opt := &option{pattern: tt.args.pattern, groupBy: tt.args.groupBy, jobs: tt.args.jobs}
+			gotItems, gotGroupData, err := opt.loadMetadata()
 			if !tt.wantErr(t, err, fmt.Sprintf("loadMetadata(%v, %v)", tt.args.pattern, tt.args.groupBy)) {
 				return
 			}
@@ -562,3 +714,65 @@ fullpath`,
 		})
 	}
 }
+
+func Test_orderFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []string
+		orderBy string
+		want    []string
+		wantErr bool
+	}{{
+		name:    "natural order",
+		files:   []string{"items/item10.yaml", "items/item2.yaml", "items/item1.yaml", "items/b.yaml"},
+		orderBy: "natural",
+		want:    []string{"items/b.yaml", "items/item1.yaml", "items/item2.yaml", "items/item10.yaml"},
+	}, {
+		name:    "natural order is the default one",
+		files:   []string{"items/item10.yaml", "items/item2.yaml"},
+		orderBy: "",
+		want:    []string{"items/item2.yaml", "items/item10.yaml"},
+	}, {
+		name:    "natural order with leading zeros",
+		files:   []string{"a/v010", "a/v10", "a/v9"},
+		orderBy: "natural",
+		want:    []string{"a/v9", "a/v10", "a/v010"},
+	}, {
+		name:    "path order",
+		files:   []string{"items/item10.yaml", "items/item2.yaml", "items/item1.yaml"},
+		orderBy: "path",
+		want:    []string{"items/item1.yaml", "items/item10.yaml", "items/item2.yaml"},
+	}, {
+		name:    "keep the original order",
+		files:   []string{"items/item10.yaml", "items/item2.yaml"},
+		orderBy: "none",
+		want:    []string{"items/item10.yaml", "items/item2.yaml"},
+	}, {
+		name:    "unknown order",
+		files:   []string{"items/item10.yaml"},
+		orderBy: "fake",
+		want:    []string{"items/item10.yaml"},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := orderFiles(tt.files, tt.orderBy)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, tt.files)
+		})
+	}
+}
+
+func Test_writeOutput(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	assert.Nil(t, (&option{}).writeOutput(stdout, []byte("content")))
+	assert.Equal(t, "content", stdout.String())
+
+	output := path.Join(t.TempDir(), "README.md")
+	stdout.Reset()
+	assert.Nil(t, (&option{output: output}).writeOutput(stdout, []byte("content")))
+	assert.Empty(t, stdout.String())
+	data, err := ioutil.ReadFile(output)
+	assert.Nil(t, err)
+	assert.Equal(t, "content", string(data))
+}
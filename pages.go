@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+)
+
+// page is the context of a paginated template
+type page struct {
+	// Items are the items of the current page
+	Items []map[string]interface{}
+	// Groups are the items of the current page which are grouped by the field of --group-by
+	Groups     map[string][]map[string]interface{}
+	Page       int
+	TotalPages int
+	// PrevURL and NextURL are the relative links of the neighbor pages, or empty if there is none
+	PrevURL string
+	NextURL string
+}
+
+// renderPages splits the items into pages, the first page is the output file, and others are page-N files beside it
+func (o *option) renderPages(readmeTpl string, items []map[string]interface{}) (err error) {
+	if o.output == "" {
+		err = fmt.Errorf("the output file is required when the page size is %d", o.pageSize)
+		return
+	}
+	if err = o.checkPrune(); err != nil {
+		return
+	}
+
+	totalPages := (len(items) + o.pageSize - 1) / o.pageSize
+	if totalPages == 0 {
+		totalPages = 1
+	}
+
+	generated := map[string]string{}
+	for i := 1; i <= totalPages; i++ {
+		start, end := (i-1)*o.pageSize, i*o.pageSize
+		if end > len(items) {
+			end = len(items)
+		}
+
+		current := &page{
+			Items:      items[start:end],
+			Groups:     groupItems(items[start:end], o.groupBy),
+			Page:       i,
+			TotalPages: totalPages,
+		}
+		if i > 1 {
+			current.PrevURL = filepath.Base(o.pageFile(i - 1))
+		}
+		if i < totalPages {
+			current.NextURL = filepath.Base(o.pageFile(i + 1))
+		}
+
+		output := o.pageFile(i)
+		generated[filepath.Clean(output)] = output
+
+		buf := bytes.NewBuffer([]byte{})
+		if err = o.renderTemplate(readmeTpl, current, buf); err != nil {
+			err = fmt.Errorf("failed to render page %d, error: %w", i, err)
+			return
+		}
+
+		var data []byte
+		if data, err = o.addHeader(o.templateFile, output, buf.Bytes()); err != nil {
+			return
+		}
+		if err = writeFile(output, data); err != nil {
+			return
+		}
+	}
+
+	if o.prune {
+		var files []string
+		if files, err = o.pageFiles(); err == nil {
+			err = o.pruneFiles(files, generated, o.templateFile)
+		}
+	}
+	return
+}
+
+// pageFiles returns the existing page-N files beside the output file
+func (o *option) pageFiles() (files []string, err error) {
+	var candidates []string
+	if candidates, err = filepath.Glob(filepath.Join(filepath.Dir(o.output), "page-[0-9]*"+filepath.Ext(o.output))); err != nil {
+		return
+	}
+
+	pageName := regexp.MustCompile(`^page-[0-9]+` + regexp.QuoteMeta(filepath.Ext(o.output)) + `$`)
+	for _, file := range candidates {
+		if pageName.MatchString(filepath.Base(file)) {
+			files = append(files, file)
+		}
+	}
+	return
+}
+
+// pageFile returns the file path of a page, the first page is the output file
+func (o *option) pageFile(index int) string {
+	if index == 1 {
+		return o.output
+	}
+	return filepath.Join(filepath.Dir(o.output), fmt.Sprintf("page-%d%s", index, filepath.Ext(o.output)))
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path"
+	"testing"
+)
+
+func Test_renderPages(t *testing.T) {
+	items := []map[string]interface{}{
+		{"name": "a", "year": 2021}, {"name": "b", "year": 2021}, {"name": "c", "year": 2022},
+		{"name": "d", "year": 2022}, {"name": "e", "year": 2022},
+	}
+	data, _ := ioutil.ReadFile("function/data/README-pages.tpl")
+	readmeTpl := string(data)
+
+	t.Run("split items into pages", func(t *testing.T) {
+		dir := t.TempDir()
+		assert.Nil(t, writeFile(path.Join(dir, "page-4.md"), []byte("---\ntitle: stale\n---\n"+
+			"<!-- This file was generated by README.tpl with the data from items/*.yaml via https://github.com/LinuxSuRen/yaml-readme, please don't edit it directly! -->\n\nstale")))
+		assert.Nil(t, writeFile(path.Join(dir, "page-template.md"), []byte("keep")))
+		// the files which were not generated by yaml-readme
+		assert.Nil(t, writeFile(path.Join(dir, "page-5.md"), []byte("keep")))
+		assert.Nil(t, writeFile(path.Join(dir, "page-2023-notes.md"), []byte("> This file was generated by [README.tpl](README.tpl) "+
+			"via [yaml-readme](https://github.com/LinuxSuRen/yaml-readme), please don't edit it directly!")))
+
+		// the stale page of another template
+		assert.Nil(t, writeFile(path.Join(dir, "page-6.md"), []byte("> This file was generated by [other.tpl](other.tpl) "+
+			"via [yaml-readme](https://github.com/LinuxSuRen/yaml-readme), please don't edit it directly!")))
+
+		opt := &option{output: path.Join(dir, "README.md"), templateFile: path.Join(dir, "README.tpl"), pageSize: 2, prune: true,
+			includeHeader: true}
+		assert.Nil(t, opt.renderPages(readmeTpl, items))
+
+		header := "> This file was generated by [README.tpl](README.tpl) via [yaml-readme](https://github.com/LinuxSuRen/yaml-readme), " +
+			"please don't edit it directly!\n\n"
+		for file, content := range map[string]string{
+			"README.md": "a b | 1/3 |  | page-2.md",
+			"page-2.md": "c d | 2/3 | README.md | page-3.md",
+			"page-3.md": "e | 3/3 | page-2.md | ",
+		} {
+			data, err := ioutil.ReadFile(path.Join(dir, file))
+			assert.Nil(t, err)
+			assert.Equal(t, header+content, string(data), file)
+		}
+		assert.FileExists(t, path.Join(dir, "page-6.md"))
+		assert.NoFileExists(t, path.Join(dir, "page-4.md"))
+		assert.FileExists(t, path.Join(dir, "page-template.md"))
+		assert.FileExists(t, path.Join(dir, "page-5.md"))
+		assert.FileExists(t, path.Join(dir, "page-2023-notes.md"))
+	})
+
+	t.Run("grouped items in a page", func(t *testing.T) {
+		output := path.Join(t.TempDir(), "README.md")
+		opt := &option{output: output, pageSize: 3, groupBy: "year"}
+		assert.Nil(t, opt.renderPages(`{{range $key, $val := .Groups}}{{$key}}:{{len $val}} {{end}}`, items))
+
+		data, err := ioutil.ReadFile(output)
+		assert.Nil(t, err)
+		assert.Equal(t, "2021:2 2022:1 ", string(data))
+	})
+
+	t.Run("no items", func(t *testing.T) {
+		output := path.Join(t.TempDir(), "README.md")
+		opt := &option{output: output, pageSize: 2}
+		assert.Nil(t, opt.renderPages(readmeTpl, nil))
+
+		data, err := ioutil.ReadFile(output)
+		assert.Nil(t, err)
+		assert.Equal(t, "| 1/1 |  | ", string(data))
+	})
+
+	t.Run("prune without the default header", func(t *testing.T) {
+		opt := &option{output: path.Join(t.TempDir(), "README.md"), pageSize: 2, prune: true}
+		assert.NotNil(t, opt.renderPages(readmeTpl, items))
+	})
+
+	t.Run("without output file", func(t *testing.T) {
+		assert.NotNil(t, (&option{pageSize: 2}).renderPages(readmeTpl, items))
+	})
+}
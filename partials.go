@@ -0,0 +1,43 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"text/template"
+)
+
+// loadPartials parses all the *.tpl files in a directory into the template set,
+// the name of each template is its slash-separated path relative to the directory
+func loadPartials(tpl *template.Template, templateDir, templateFile string) (err error) {
+	if templateDir == "" {
+		return
+	}
+
+	var mainTemplate string
+	if templateFile != "" {
+		mainTemplate, _ = filepath.Abs(templateFile)
+	}
+
+	err = filepath.Walk(templateDir, func(path string, info os.FileInfo, walkErr error) (err error) {
+		if err = walkErr; err != nil || info.IsDir() || filepath.Ext(path) != ".tpl" {
+			return
+		}
+
+		// the main template is not a partial one
+		if absPath, _ := filepath.Abs(path); absPath == mainTemplate {
+			return
+		}
+
+		var name string
+		var data []byte
+		if name, err = filepath.Rel(templateDir, path); err != nil {
+			return
+		}
+		if data, err = ioutil.ReadFile(path); err == nil {
+			_, err = tpl.New(filepath.ToSlash(name)).Parse(removeDirective(string(data)))
+		}
+		return
+	})
+	return
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"text/template"
+)
+
+func Test_loadPartials(t *testing.T) {
+	tests := []struct {
+		name         string
+		templateDir  string
+		templateFile string
+		wantNames    []string
+		wantErr      bool
+	}{{
+		name: "no template directory",
+	}, {
+		name:         "all the templates except the main one",
+		templateDir:  "function/data/templates",
+		templateFile: "function/data/templates/README.tpl",
+		wantNames:    []string{"footer", "footer.tpl", "partials/row.tpl"},
+	}, {
+		name:        "not exist directory",
+		templateDir: "fake",
+		wantErr:     true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tpl := template.New("readme")
+			err := loadPartials(tpl, tt.templateDir, tt.templateFile)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if !tt.wantErr {
+				var names []string
+				for _, item := range tpl.Templates() {
+					names = append(names, item.Name())
+				}
+				assert.ElementsMatch(t, tt.wantNames, names)
+			}
+		})
+	}
+}
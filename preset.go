@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// builtInVariables are the variables which are added by yaml-readme instead of the item files
+var builtInVariables = map[string]bool{
+	"filename":   true,
+	"parentname": true,
+	"fullpath":   true,
+	"ignore":     true,
+}
+
+// generatePreset generates a template from the union of the item keys, it uses the delimiters of the option.
+// The fields are accessed via the index function, so the items which lack some fields work with --missing-key error.
+func (o *option) generatePreset(preset string, items []map[string]interface{}) (tpl string, err error) {
+	if (preset == "grouped-table") != (o.groupBy != "") {
+		err = fmt.Errorf("the preset grouped-table works with --group-by, and others work without it")
+		return
+	}
+
+	var actions presetActions
+	if actions.left, actions.right, err = o.getDelims(); err != nil {
+		return
+	} else if actions.left == "" {
+		actions.left, actions.right = "{{", "}}"
+	}
+
+	fields := collectFields(items, o.groupBy)
+	switch preset {
+	case "table":
+		tpl = tableHeader(fields) + `
+` + actions.action("- range $val := .") + `
+` + actions.tableRow("$val", fields) + `
+` + actions.action("- end")
+	case "grouped-table":
+		tpl = actions.action("- range $key, $val := .") + `
+
+## ` + actions.action("$key") + `
+
+` + tableHeader(fields) + `
+` + actions.action("- range $item := $val") + `
+` + actions.tableRow("$item", fields) + `
+` + actions.action("- end") + `
+` + actions.action("- end")
+	case "list":
+		tpl = actions.action("- range $val := .") + `
+- ` + actions.fieldValue("$val", titleField(fields))
+		for _, field := range fields {
+			if field != titleField(fields) {
+				tpl += fmt.Sprintf("\n  - %s: %s", fieldTitle(field), actions.fieldValue("$val", field))
+			}
+		}
+		tpl += "\n" + actions.action("- end")
+	case "cards":
+		tpl = actions.action("- range $val := .") + `
+
+### ` + actions.fieldValue("$val", titleField(fields)) + `
+
+| | |
+|---|---|`
+		for _, field := range fields {
+			if field != titleField(fields) {
+				tpl += fmt.Sprintf("\n| %s | %s |", fieldTitle(field), actions.fieldValue("$val", field))
+			}
+		}
+		tpl += "\n" + actions.action("- end")
+	default:
+		err = fmt.Errorf("unsupported preset %q, it should be one of table, list, cards, grouped-table", preset)
+	}
+	return
+}
+
+// presetActions builds the template actions with the delimiters
+type presetActions struct {
+	left  string
+	right string
+}
+
+func (a presetActions) action(pipeline string) string {
+	return a.left + pipeline + a.right
+}
+
+// defaultPreset returns the preset which fits the data
+func defaultPreset(groupBy string) string {
+	if groupBy != "" {
+		return "grouped-table"
+	}
+	return "table"
+}
+
+// collectFields returns the sorted union of the item keys except the built-in variables and the group field
+func collectFields(items []map[string]interface{}, groupBy string) (fields []string) {
+	exist := map[string]bool{}
+	for _, item := range items {
+		for key := range item {
+			if !builtInVariables[key] && key != groupBy && !exist[key] {
+				exist[key] = true
+				fields = append(fields, key)
+			}
+		}
+	}
+	sort.Strings(fields)
+	return
+}
+
+// titleField returns the field which stands for an item, it prefers name and title
+func titleField(fields []string) (field string) {
+	for _, candidate := range []string{"name", "title"} {
+		for _, item := range fields {
+			if item == candidate {
+				return item
+			}
+		}
+	}
+	if len(fields) > 0 {
+		field = fields[0]
+	}
+	return
+}
+
+func tableHeader(fields []string) string {
+	titles := make([]string, len(fields))
+	separators := make([]string, len(fields))
+	for i, field := range fields {
+		titles[i] = fieldTitle(field)
+		separators[i] = "---"
+	}
+	return fmt.Sprintf("| %s |\n|%s|", strings.Join(titles, " | "), strings.Join(separators, "|"))
+}
+
+func (a presetActions) tableRow(variable string, fields []string) string {
+	values := make([]string, len(fields))
+	for i, field := range fields {
+		values[i] = a.fieldValue(variable, field)
+	}
+	return fmt.Sprintf("| %s |", strings.Join(values, " | "))
+}
+
+// fieldTitle makes the first letter of a field be upper case
+func fieldTitle(field string) string {
+	runes := []rune(field)
+	if len(runes) > 0 {
+		runes[0] = unicode.ToUpper(runes[0])
+	}
+	return string(runes)
+}
+
+// fieldValue returns the template action of a field, it is empty in case the item lacks the field
+func (a presetActions) fieldValue(variable, field string) string {
+	if field == "" {
+		return ""
+	}
+	return a.action(fmt.Sprintf("if hasKey %s %q", variable, field)) +
+		a.action(fmt.Sprintf("index %s %q", variable, field)) + a.action("end")
+}
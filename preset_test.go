@@ -0,0 +1,107 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_generatePreset(t *testing.T) {
+	items := []map[string]interface{}{{
+		"name": "yaml-readme", "group/key": "value", "filename": "yaml-readme",
+	}, {
+		"name": "hd", "description": "a downloader",
+	}}
+	tests := []struct {
+		name    string
+		preset  string
+		groupBy string
+		delims  string
+		want    string
+		wantErr bool
+	}{{
+		name:   "table",
+		preset: "table",
+		want: `| Description | Group/key | Name |
+|---|---|---|
+{{- range $val := .}}
+| {{if hasKey $val "description"}}{{index $val "description"}}{{end}} | {{if hasKey $val "group/key"}}{{index $val "group/key"}}{{end}} | {{if hasKey $val "name"}}{{index $val "name"}}{{end}} |
+{{- end}}`,
+	}, {
+		name:   "cards",
+		preset: "cards",
+		want: `{{- range $val := .}}
+
+### {{if hasKey $val "name"}}{{index $val "name"}}{{end}}
+
+| | |
+|---|---|
+| Description | {{if hasKey $val "description"}}{{index $val "description"}}{{end}} |
+| Group/key | {{if hasKey $val "group/key"}}{{index $val "group/key"}}{{end}} |
+{{- end}}`,
+	}, {
+		name:   "list with delimiters",
+		preset: "list",
+		delims: "[[,]]",
+		want: `[[- range $val := .]]
+- [[if hasKey $val "name"]][[index $val "name"]][[end]]
+  - Description: [[if hasKey $val "description"]][[index $val "description"]][[end]]
+  - Group/key: [[if hasKey $val "group/key"]][[index $val "group/key"]][[end]]
+[[- end]]`,
+	}, {
+		name:    "grouped table",
+		preset:  "grouped-table",
+		groupBy: "name",
+		want: `{{- range $key, $val := .}}
+
+## {{$key}}
+
+| Description | Group/key |
+|---|---|
+{{- range $item := $val}}
+| {{if hasKey $item "description"}}{{index $item "description"}}{{end}} | {{if hasKey $item "group/key"}}{{index $item "group/key"}}{{end}} |
+{{- end}}
+{{- end}}`,
+	}, {
+		name:    "invalid delimiters",
+		preset:  "table",
+		delims:  "[[",
+		wantErr: true,
+	}, {
+		name:    "grouped table without group field",
+		preset:  "grouped-table",
+		wantErr: true,
+	}, {
+		name:    "table with group field",
+		preset:  "table",
+		groupBy: "name",
+		wantErr: true,
+	}, {
+		name:    "unknown preset",
+		preset:  "fake",
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tpl, err := (&option{groupBy: tt.groupBy, delims: tt.delims}).generatePreset(tt.preset, items)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, tpl)
+		})
+	}
+}
+
+func Test_generatePresetWithMissingKey(t *testing.T) {
+	items := []map[string]interface{}{{"name": "yaml-readme", "stable": false}, {"name": "hd", "description": "a downloader"}}
+	for _, missingKey := range []string{"error", "zero", "default"} {
+		opt := &option{missingKey: missingKey}
+		tpl, err := opt.generatePreset("table", items)
+		assert.Nil(t, err)
+
+		// the items lack some fields
+		output, err := opt.renderTemplateToString(tpl, items)
+		assert.Nil(t, err)
+		assert.Equal(t, `| Description | Name | Stable |
+|---|---|---|
+|  | yaml-readme | false |
+| a downloader | hd |  |`, output, missingKey)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// remoteClient is the HTTP client of the remote data, a server which does not respond would not block the rendering
+var remoteClient = &http.Client{Timeout: 30 * time.Second}
+
+// isRemote determines if the pattern is an HTTP(S) URL
+func isRemote(pattern string) bool {
+	return strings.HasPrefix(pattern, "http://") || strings.HasPrefix(pattern, "https://")
+}
+
+// defaultCacheDir returns the directory to cache the remote data
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "yaml-readme")
+}
+
+// fetchWithCache downloads the data from a URL, and caches it in a local directory.
+// The cache will be revalidated with the ETag, and be used in case the network is not available.
+func fetchWithCache(api, cacheDir string) (data []byte, err error) {
+	key := fmt.Sprintf("%x", sha256.Sum256([]byte(api)))
+	dataFile := filepath.Join(cacheDir, key)
+	etagFile := dataFile + ".etag"
+
+	cache, cacheErr := ioutil.ReadFile(dataFile)
+
+	var (
+		req  *http.Request
+		resp *http.Response
+	)
+	if req, err = http.NewRequest(http.MethodGet, api, nil); err != nil {
+		return
+	}
+	if etag, etagErr := ioutil.ReadFile(etagFile); etagErr == nil && cacheErr == nil {
+		req.Header.Set("If-None-Match", string(etag))
+	}
+
+	if resp, err = remoteClient.Do(req); err != nil {
+		if cacheErr == nil {
+			logger.Printf("failed to request [%s], use the cache instead, error: %v\n", api, err)
+			data, err = cache, nil
+		}
+		return
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if data, err = ioutil.ReadAll(resp.Body); err == nil {
+			saveCache(dataFile, etagFile, data, resp.Header.Get("ETag"))
+		}
+	case http.StatusNotModified:
+		data = cache
+	default:
+		if cacheErr == nil {
+			logger.Printf("unexpected status code %d from [%s], use the cache instead\n", resp.StatusCode, api)
+			data = cache
+		} else {
+			err = fmt.Errorf("unexpected status code %d from %q", resp.StatusCode, api)
+		}
+	}
+	return
+}
+
+func saveCache(dataFile, etagFile string, data []byte, etag string) {
+	var err error
+	if err = os.MkdirAll(filepath.Dir(dataFile), 0755); err == nil {
+		if err = ioutil.WriteFile(dataFile, data, 0644); err == nil {
+			if etag != "" {
+				err = ioutil.WriteFile(etagFile, []byte(etag), 0644)
+			} else {
+				err = os.RemoveAll(etagFile)
+			}
+		}
+	}
+	if err != nil {
+		logger.Printf("failed to cache [%s], error: %v\n", dataFile, err)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_isRemote(t *testing.T) {
+	assert.True(t, isRemote("https://github.com/linuxsuren/yaml-readme/items.yaml"))
+	assert.True(t, isRemote("http://localhost/items.yaml"))
+	assert.False(t, isRemote("items/*.yaml"))
+	assert.False(t, isRemote("-"))
+}
+
+func Test_fetchWithCache(t *testing.T) {
+	var requests, revalidated int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			revalidated++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`[{"name": "rick"}]`))
+	}))
+	api := server.URL + "/items.json"
+	cacheDir := t.TempDir()
+
+	// fetch it from the server
+	data, err := fetchWithCache(api, cacheDir)
+	assert.Nil(t, err)
+	assert.Equal(t, `[{"name": "rick"}]`, string(data))
+
+	// revalidate the cache with ETag
+	data, err = fetchWithCache(api, cacheDir)
+	assert.Nil(t, err)
+	assert.Equal(t, `[{"name": "rick"}]`, string(data))
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, 1, revalidated)
+
+	// use the cache when the server is not available
+	server.Close()
+	data, err = fetchWithCache(api, cacheDir)
+	assert.Nil(t, err)
+	assert.Equal(t, `[{"name": "rick"}]`, string(data))
+
+	// there is no cache
+	_, err = fetchWithCache(api, t.TempDir())
+	assert.NotNil(t, err)
+}
+
+func Test_fetchWithCache_unexpectedStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	_, err := fetchWithCache(server.URL, t.TempDir())
+	assert.NotNil(t, err)
+}
+
+func Test_loadMetadata_remote(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`- name: rick
+- name: linuxsuren`))
+	}))
+	defer server.Close()
+
+	opt := &option{pattern: server.URL + "/data/items.yaml", cacheDir: t.TempDir()}
+	items, _, err := opt.loadMetadata()
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(items)) {
+		assert.Equal(t, "rick", items[0]["name"])
+		assert.Equal(t, "items", items[0]["filename"])
+		assert.Equal(t, "linuxsuren", items[1]["name"])
+	}
+}
+
+func Test_loadMetadata_remoteUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	api := server.URL + "/data/items.yaml"
+	server.Close()
+
+	// a missing remote data source fails the run instead of rendering nothing
+	opt := &option{pattern: api, cacheDir: t.TempDir()}
+	_, _, err := opt.loadMetadata()
+	assert.NotNil(t, err)
+}
+
+func Test_fetchWithCache_timeout(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer server.Close()
+	defer close(done)
+
+	timeout := remoteClient.Timeout
+	remoteClient.Timeout = 100 * time.Millisecond
+	defer func() {
+		remoteClient.Timeout = timeout
+	}()
+
+	_, err := fetchWithCache(server.URL, t.TempDir())
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// the region markers could be in the comments of most languages, for instance: // region: name, # endregion
+	regionPattern    = regexp.MustCompile(`^\s*(?://|#|--|;|<!--|/\*)\s*region:\s*(\S+)`)
+	endRegionPattern = regexp.MustCompile(`^\s*(?://|#|--|;|<!--|/\*)\s*endregion\b`)
+	// the line range looks like the anchor of GitHub, for instance: L10-20 or L10-L20
+	lineRangePattern = regexp.MustCompile(`^L(\d+)(?:-L?(\d+))?$`)
+)
+
+// snippetLanguages are the languages of the code blocks which are different from the file extensions
+var snippetLanguages = map[string]string{
+	".sh":   "shell",
+	".bash": "shell",
+	".py":   "python",
+	".js":   "javascript",
+	".ts":   "typescript",
+	".rs":   "rust",
+	".rb":   "ruby",
+	".kt":   "kotlin",
+	".yml":  "yaml",
+	".md":   "markdown",
+	".tpl":  "gotemplate",
+}
+
+// snippet returns a region or a line range of a file in the scope as a code block.
+// The region is the lines between "// region: name" and "// endregion", the line range looks like L10-20.
+func snippet(scope fileScope, path, region string) (output string, err error) {
+	var content string
+	if content, err = scope.readFile(path); err != nil {
+		return
+	}
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+
+	var selected []string
+	if match := lineRangePattern.FindStringSubmatch(region); match != nil {
+		selected, err = selectLines(lines, match[1], match[2])
+	} else {
+		selected, err = selectRegion(lines, region)
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to get the snippet %q of %q, error: %v", region, path, err)
+		return
+	}
+
+	code := strings.Join(dedent(selected), "\n")
+	fence := codeFence(code)
+	output = fmt.Sprintf("%s%s\n%s\n%s", fence, snippetLanguage(path), code, fence)
+	return
+}
+
+// selectLines returns the lines in the range, the line number starts from 1
+func selectLines(lines []string, start, end string) (selected []string, err error) {
+	from, _ := strconv.Atoi(start)
+	to := from
+	if end != "" {
+		to, _ = strconv.Atoi(end)
+	}
+	if from < 1 || from > to || to > len(lines) {
+		err = fmt.Errorf("invalid line range %d-%d, the file has %d lines", from, to, len(lines))
+		return
+	}
+	selected = lines[from-1 : to]
+	return
+}
+
+// selectRegion returns the lines of the region, the markers of the nested regions are removed
+func selectRegion(lines []string, region string) (selected []string, err error) {
+	depth := 0
+	for _, line := range lines {
+		if match := regionPattern.FindStringSubmatch(line); match != nil {
+			if depth > 0 {
+				depth++
+			} else if match[1] == region {
+				depth = 1
+			}
+			continue
+		}
+		if endRegionPattern.MatchString(line) {
+			if depth == 1 {
+				return
+			} else if depth > 1 {
+				depth--
+			}
+			continue
+		}
+		if depth > 0 {
+			selected = append(selected, line)
+		}
+	}
+
+	if depth > 0 {
+		err = fmt.Errorf("region %q is not closed", region)
+	} else {
+		err = fmt.Errorf("region %q is not found", region)
+	}
+	return
+}
+
+// dedent removes the common leading whitespaces of the lines, and the leading and trailing empty lines
+func dedent(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	var prefix string
+	first := true
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if first {
+			prefix, first = indent, false
+		}
+		for !strings.HasPrefix(indent, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+
+	result := make([]string, len(lines))
+	for i, line := range lines {
+		result[i] = strings.TrimRight(strings.TrimPrefix(line, prefix), " \t")
+	}
+	return result
+}
+
+// codeFence returns a fence which is longer than the backticks in the code
+func codeFence(code string) string {
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	return fence
+}
+
+// snippetLanguage returns the language of the code block by the file extension
+func snippetLanguage(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	if language, ok := snippetLanguages[ext]; ok {
+		return language
+	}
+	if ext == "" {
+		return strings.ToLower(filepath.Base(path))
+	}
+	return strings.TrimPrefix(ext, ".")
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+const snippetSource = `package main
+
+func main() {
+	// region: hello
+	if true {
+		println("hello")
+	}
+	// region: nested
+	println("nested")
+	// endregion
+	// endregion
+}
+
+// region: 10
+println("ten")
+// endregion
+
+// region: open
+`
+
+func Test_snippet(t *testing.T) {
+	dir := t.TempDir()
+	err := ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte(snippetSource), 0644)
+	assert.Nil(t, err)
+	err = ioutil.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM alpine\n```\n"), 0644)
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name     string
+		path     string
+		region   string
+		expect   string
+		hasError bool
+	}{{
+		name:   "region with a nested one",
+		path:   "main.go",
+		region: "hello",
+		expect: "```go\nif true {\n\tprintln(\"hello\")\n}\nprintln(\"nested\")\n```",
+	}, {
+		name:   "nested region",
+		path:   "main.go",
+		region: "nested",
+		expect: "```go\nprintln(\"nested\")\n```",
+	}, {
+		name:   "line range",
+		path:   "main.go",
+		region: "L5-7",
+		expect: "```go\nif true {\n\tprintln(\"hello\")\n}\n```",
+	}, {
+		name:   "single line",
+		path:   "main.go",
+		region: "L1",
+		expect: "```go\npackage main\n```",
+	}, {
+		name:   "line range like the anchor of GitHub",
+		path:   "main.go",
+		region: "L1-L1",
+		expect: "```go\npackage main\n```",
+	}, {
+		name:   "region with a number as its name",
+		path:   "main.go",
+		region: "10",
+		expect: "```go\nprintln(\"ten\")\n```",
+	}, {
+		name:   "language from the file name and a longer fence",
+		path:   "Dockerfile",
+		region: "L1-2",
+		expect: "````dockerfile\nFROM alpine\n```\n````",
+	}, {
+		name:     "region is not found",
+		path:     "main.go",
+		region:   "fake",
+		hasError: true,
+	}, {
+		name:     "region is not closed",
+		path:     "main.go",
+		region:   "open",
+		hasError: true,
+	}, {
+		name:     "invalid line range",
+		path:     "main.go",
+		region:   "L7-5",
+		hasError: true,
+	}, {
+		name:     "file does not exist",
+		path:     "fake.go",
+		region:   "L1",
+		hasError: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := snippet(newFileScope(filepath.Join(dir, "README.tpl")), tt.path, tt.region)
+			if tt.hasError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expect, output)
+			}
+		})
+	}
+}
+
+func Test_dedent(t *testing.T) {
+	assert.Equal(t, []string{"a", "", "  b", "c"}, dedent([]string{"", "    a  ", "", "      b", "    c", "  "}))
+	assert.Equal(t, []string{"\ta", "b"}, dedent([]string{" \ta", " b"}))
+	assert.Equal(t, []string{}, dedent([]string{" ", ""}))
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// tocOption is the option of the TOC
+type tocOption struct {
+	// minDepth and maxDepth are the levels of the headings which are listed in the TOC
+	minDepth int
+	maxDepth int
+	// ordered indicates if it is an ordered list or a bulleted list
+	ordered bool
+}
+
+// defaultTOCOption lists the headings from ## to ### as a bulleted list
+var defaultTOCOption = tocOption{minDepth: 2, maxDepth: 3}
+
+// getTOCOption returns the option of the TOC, the zero depths fall back to the default ones
+func (o *option) getTOCOption() (toc tocOption, err error) {
+	toc = tocOption{minDepth: o.tocMinDepth, maxDepth: o.tocMaxDepth, ordered: o.tocOrdered}
+	if toc.minDepth == 0 {
+		toc.minDepth = defaultTOCOption.minDepth
+	}
+	if toc.maxDepth == 0 {
+		toc.maxDepth = defaultTOCOption.maxDepth
+	}
+	if toc.minDepth < 1 || toc.maxDepth > 6 || toc.minDepth > toc.maxDepth {
+		err = fmt.Errorf("invalid TOC depth from %d to %d, it should be 1 <= min <= max <= 6", toc.minDepth, toc.maxDepth)
+	}
+	return
+}
+
+// tocPlaceholder is the output of printToc, it is replaced with the TOC of the rendered content
+const tocPlaceholder = "<!-- yaml-readme:toc -->"
+
+// heading is a Markdown heading
+type heading struct {
+	level int
+	text  string
+}
+
+var (
+	atxHeadingPattern    = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	setextHeadingPattern = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	fencePattern         = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+	blockPattern         = regexp.MustCompile(`^ {0,3}([-*+>|]|\d+[.)])`)
+	imagePattern         = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	linkPattern          = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	htmlTagPattern       = regexp.MustCompile(`<[^>]+>`)
+)
+
+// generateTOC generates the TOC of the Markdown headings, the anchors are the same as GitHub's
+func generateTOC(txt string, option tocOption) (toc string) {
+	_, txt = splitFrontMatter(txt)
+
+	anchors := map[string]int{}
+	counters := make([]int, 7)
+	for _, h := range parseHeadings(txt) {
+		// every heading takes an anchor, even if it is not listed in the TOC
+		anchor := headingAnchor(h.text, anchors)
+		if h.level < option.minDepth || h.level > option.maxDepth {
+			continue
+		}
+
+		indent := h.level - option.minDepth
+		marker := "-"
+		if option.ordered {
+			counters[h.level]++
+			for i := h.level + 1; i < len(counters); i++ {
+				counters[i] = 0
+			}
+			marker = fmt.Sprintf("%d.", counters[h.level])
+			indent *= 3
+		} else {
+			indent *= 2
+		}
+		toc += fmt.Sprintf("%s%s [%s](#%s)\n", strings.Repeat(" ", indent), marker, headingText(h.text), anchor)
+	}
+	return
+}
+
+// parseHeadings returns the ATX and setext headings which are not in the fenced code blocks
+func parseHeadings(txt string) (headings []heading) {
+	var fence string
+	var previous string
+	for _, line := range strings.Split(txt, "\n") {
+		line = strings.TrimRight(line, "\r")
+
+		if fence != "" {
+			if strings.HasPrefix(strings.TrimSpace(line), fence) &&
+				strings.Trim(strings.TrimSpace(line), fence[:1]) == "" {
+				fence = ""
+			}
+			continue
+		}
+		if match := fencePattern.FindStringSubmatch(line); match != nil {
+			fence, previous = match[1], ""
+			continue
+		}
+
+		if match := atxHeadingPattern.FindStringSubmatch(line); match != nil {
+			headings = append(headings, heading{level: len(match[1]), text: match[2]})
+			previous = ""
+			continue
+		}
+		if match := setextHeadingPattern.FindStringSubmatch(line); match != nil && previous != "" {
+			level := 1
+			if match[1][0] == '-' {
+				level = 2
+			}
+			headings = append(headings, heading{level: level, text: strings.TrimSpace(previous)})
+			previous = ""
+			continue
+		}
+
+		// only a paragraph line could be the text of a setext heading
+		previous = ""
+		if strings.TrimSpace(line) != "" && !blockPattern.MatchString(line) && !strings.HasPrefix(line, "    ") {
+			previous = line
+		}
+	}
+	return
+}
+
+// headingText returns the plain text of a heading without links, images, and HTML tags
+func headingText(text string) string {
+	text = imagePattern.ReplaceAllString(text, "$1")
+	text = linkPattern.ReplaceAllString(text, "$1")
+	text = htmlTagPattern.ReplaceAllString(text, "")
+	return strings.TrimSpace(text)
+}
+
+// headingAnchor returns the anchor of a heading in the same way as GitHub,
+// the duplicated anchors have the suffixes -1, -2, etc.
+func headingAnchor(text string, anchors map[string]int) (anchor string) {
+	var builder strings.Builder
+	for _, r := range strings.ToLower(headingText(text)) {
+		switch {
+		case r == ' ':
+			builder.WriteRune('-')
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsMark(r):
+			builder.WriteRune(r)
+		}
+	}
+	anchor = builder.String()
+
+	// the anchor with a suffix might be used by another heading, such as Usage-1
+	original := anchor
+	for _, ok := anchors[anchor]; ok; _, ok = anchors[anchor] {
+		anchors[original]++
+		anchor = fmt.Sprintf("%s-%d", original, anchors[original])
+	}
+	anchors[anchor] = 0
+	return
+}
+
+// fillTOC replaces the TOC placeholders with the TOC of the rendered content
+func fillTOC(content string, option tocOption) string {
+	if !strings.Contains(content, tocPlaceholder) {
+		return content
+	}
+	toc := generateTOC(strings.ReplaceAll(content, tocPlaceholder, ""), option)
+	return strings.ReplaceAll(content, tocPlaceholder, toc)
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_generateTOC(t *testing.T) {
+	tests := []struct {
+		name    string
+		txt     string
+		option  tocOption
+		wantToc string
+	}{{
+		name:    "simple text",
+		txt:     `## Good`,
+		option:  defaultTOCOption,
+		wantToc: "- [Good](#good)\n",
+	}, {
+		name: "multiple levels of the titles",
+		txt: `## Good
+content
+### Better`,
+		option:  defaultTOCOption,
+		wantToc: "- [Good](#good)\n  - [Better](#better)\n",
+	}, {
+		name: "has whitespace between title",
+		txt: `## Good
+## This is good`,
+		option:  defaultTOCOption,
+		wantToc: "- [Good](#good)\n- [This is good](#this-is-good)\n",
+	}, {
+		name: "punctuation, unicode, and closing hashes",
+		txt: `## What's new? ##
+## C++ & Go: v1.0
+## 中文 标题
+## Hello_World -- Done`,
+		option: defaultTOCOption,
+		wantToc: `- [What's new?](#whats-new)
+- [C++ & Go: v1.0](#c--go-v10)
+- [中文 标题](#中文-标题)
+- [Hello_World -- Done](#hello_world----done)
+`,
+	}, {
+		name: "duplicated titles",
+		txt: `## Usage
+## Usage
+### Usage`,
+		option:  defaultTOCOption,
+		wantToc: "- [Usage](#usage)\n- [Usage](#usage-1)\n  - [Usage](#usage-2)\n",
+	}, {
+		name:    "duplicated titles with a suffix",
+		txt:     "## Usage\n## Usage-1\n## Usage\n## Usage",
+		option:  defaultTOCOption,
+		wantToc: "- [Usage](#usage)\n- [Usage-1](#usage-1)\n- [Usage](#usage-2)\n- [Usage](#usage-3)\n",
+	}, {
+		name:    "links and inline code",
+		txt:     "## [yaml-readme](https://github.com/linuxsuren/yaml-readme) `v1`",
+		option:  defaultTOCOption,
+		wantToc: "- [yaml-readme `v1`](#yaml-readme-v1)\n",
+	}, {
+		name:    "fenced code blocks",
+		txt:     "## Install\n```shell\n# not a title\n```\n~~~~\n## not a title\n```\n~~~~\n## Usage",
+		option:  defaultTOCOption,
+		wantToc: "- [Install](#install)\n- [Usage](#usage)\n",
+	}, {
+		name: "setext titles",
+		txt: `Title
+=====
+
+Install
+-------
+
+- item
+---
+
+---
+
+Usage
+---`,
+		option:  tocOption{minDepth: 1, maxDepth: 2},
+		wantToc: "- [Title](#title)\n  - [Install](#install)\n  - [Usage](#usage)\n",
+	}, {
+		name: "front matter is not a title",
+		txt: `---
+title: fake
+---
+## Good`,
+		option:  defaultTOCOption,
+		wantToc: "- [Good](#good)\n",
+	}, {
+		name: "depth",
+		txt: `# Title
+## Install
+### From source
+#### Build`,
+		option:  tocOption{minDepth: 3, maxDepth: 4},
+		wantToc: "- [From source](#from-source)\n  - [Build](#build)\n",
+	}, {
+		name: "ordered list",
+		txt: `## Install
+### From source
+### From binary
+## Usage
+### Flags`,
+		option: tocOption{minDepth: 2, maxDepth: 3, ordered: true},
+		wantToc: `1. [Install](#install)
+   1. [From source](#from-source)
+   2. [From binary](#from-binary)
+2. [Usage](#usage)
+   1. [Flags](#flags)
+`,
+	}, {
+		name:    "not titles",
+		txt:     "#hashtag\n    ## indented code\n#!yaml-readme -p items/*.yaml",
+		option:  defaultTOCOption,
+		wantToc: "",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantToc, generateTOC(tt.txt, tt.option))
+		})
+	}
+}
+
+func Test_fillTOC(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		expect  string
+	}{{
+		name:    "no placeholder",
+		content: "## Good",
+		expect:  "## Good",
+	}, {
+		name:    "headings after the placeholder",
+		content: tocPlaceholder + "\n## 2021\n## 2022",
+		expect:  "- [2021](#2021)\n- [2022](#2022)\n\n## 2021\n## 2022",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, fillTOC(tt.content, defaultTOCOption))
+		})
+	}
+}
+
+func Test_getTOCOption(t *testing.T) {
+	tests := []struct {
+		name     string
+		opt      *option
+		expect   tocOption
+		hasError bool
+	}{{
+		name:   "default",
+		opt:    &option{},
+		expect: defaultTOCOption,
+	}, {
+		name:   "ordered with depth",
+		opt:    &option{tocMinDepth: 1, tocMaxDepth: 4, tocOrdered: true},
+		expect: tocOption{minDepth: 1, maxDepth: 4, ordered: true},
+	}, {
+		name:     "min depth is greater than max depth",
+		opt:      &option{tocMinDepth: 4, tocMaxDepth: 2},
+		hasError: true,
+	}, {
+		name:     "max depth is out of range",
+		opt:      &option{tocMaxDepth: 7},
+		hasError: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toc, err := tt.opt.getTOCOption()
+			if tt.hasError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expect, toc)
+			}
+		})
+	}
+}